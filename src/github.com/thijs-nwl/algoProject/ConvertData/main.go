@@ -0,0 +1,80 @@
+// ConvertData converts candle files between the JSON the datastore keeps
+// and the compact candlebin format.
+//
+// Usage:
+//
+//	ConvertData -to bin -gzip ../datastore/BTC_XMR_1512086400_1516406400_ btc_xmr.bin
+//	ConvertData -to json btc_xmr.bin btc_xmr.json
+//	ConvertData -info btc_xmr.bin
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/thijs-nwl/algoProject/candlebin"
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+var (
+	toFlag       = flag.String("to", "bin", "output `format`: bin or json")
+	periodFlag   = flag.Int("period", int(market.Period5m), "candle period in seconds, recorded in the bin header")
+	gzipFlag     = flag.Bool("gzip", false, "compress the bin output")
+	columnarFlag = flag.Bool("columnar", false, "store the bin output column by column")
+	infoFlag     = flag.Bool("info", false, "print the header and range of a bin file")
+)
+
+func main() {
+	log.SetFlags(0)
+	flag.Parse()
+
+	if *infoFlag {
+		if flag.NArg() != 1 {
+			log.Fatal("usage: ConvertData -info file.bin")
+		}
+		info(flag.Arg(0))
+		return
+	}
+
+	if flag.NArg() != 2 {
+		log.Fatal("usage: ConvertData -to bin|json src dst")
+	}
+	src, dst := flag.Arg(0), flag.Arg(1)
+
+	var err error
+	switch *toFlag {
+	case "bin":
+		err = candlebin.FromJSON(src, dst, market.Period(*periodFlag), candlebin.Options{Compress: *gzipFlag, Columnar: *columnarFlag})
+	case "json":
+		err = candlebin.ToJSON(src, dst)
+	default:
+		log.Fatalf("unknown format %q", *toFlag)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func info(path string) {
+	f, err := candlebin.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	h := f.Header()
+	fmt.Printf("version %d, period %v, %d candles, compressed %v, columnar %v\n", h.Version, h.Period, h.Count, h.Compressed, h.Columnar)
+	if f.Len() == 0 {
+		return
+	}
+	first, err := f.At(0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	last, err := f.At(f.Len() - 1)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%v - %v\n", first.Time(), last.Time())
+}
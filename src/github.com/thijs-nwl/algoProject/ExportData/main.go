@@ -0,0 +1,95 @@
+// ExportData writes a stored candle series as CSV or in the columnar
+// candlebin format, for use in spreadsheets and notebooks.
+//
+// Usage:
+//
+//	ExportData -pair BTC_XMR -columns date,close,volume -time 2006-01-02T15:04 -o btc_xmr.csv
+//	ExportData -pair BTC_XMR -format columnar -gzip -o btc_xmr.bin
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/candlebin"
+	"github.com/thijs-nwl/algoProject/csvfile"
+	"github.com/thijs-nwl/algoProject/datastore"
+	"github.com/thijs-nwl/algoProject/market"
+	"github.com/thijs-nwl/algoProject/timespec"
+)
+
+var (
+	dirFlag      = flag.String("dir", "../datastore", "datastore `directory`")
+	backendFlag  = flag.String("backend", "file", "candle storage: file or db")
+	exchangeFlag = flag.String("exchange", "poloniex", "exchange the series is keyed by in -backend db")
+	pairFlag     = flag.String("pair", "BTC_XMR", "pair to export")
+	periodFlag   = flag.Int("period", int(market.Period5m), "candle period in seconds")
+	startFlag    = flag.String("start", "", "first `time` to export, as a Unix time, 2017-12-01 or 2017-12-01 15:04; defaults to the start of the data")
+	endFlag      = flag.String("end", "", "last `time` to export, in the same forms as -start; a date alone includes the whole day; defaults to the end of the data")
+	formatFlag   = flag.String("format", "csv", "output `format`: csv, bin or columnar")
+	columnsFlag  = flag.String("columns", "", "comma separated CSV `columns`, from date,open,high,low,close,volume,quoteVolume,weightedAverage; all when empty")
+	timeFlag     = flag.String("time", "unix", "CSV date `format`: unix, unixms or a Go time layout such as 2006-01-02T15:04:05Z07:00")
+	gzipFlag     = flag.Bool("gzip", false, "compress bin and columnar output")
+	outFlag      = flag.String("o", "", "output `file`; standard output when empty")
+)
+
+func main() {
+	log.SetFlags(0)
+	flag.Parse()
+
+	pair, err := market.ParsePair(*pairFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	period := market.Period(*periodFlag)
+	if !period.Valid() {
+		log.Fatalf("invalid period %d, want one of %v", *periodFlag, market.Periods)
+	}
+	start, end, err := timespec.Range(*startFlag, *endFlag, time.Now())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	store, err := datastore.Open(datastore.Config{Backend: *backendFlag, Dir: *dirFlag, Exchange: market.Exchange(*exchangeFlag)})
+	if err != nil {
+		log.Fatal(err)
+	}
+	candles, err := store.Load(pair, period, start, end)
+	store.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(candles) == 0 {
+		log.Fatalf("no %v candles of %v seconds stored in that range", pair, period)
+	}
+
+	var out io.Writer = os.Stdout
+	if *outFlag != "" {
+		f, err := os.Create(*outFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	switch *formatFlag {
+	case "csv":
+		columns, err := csvfile.ParseColumns(*columnsFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = csvfile.Write(out, candles, csvfile.WriteOptions{Columns: columns, TimeFormat: *timeFlag})
+	case "bin", "columnar":
+		opts := candlebin.Options{Compress: *gzipFlag, Columnar: *formatFlag == "columnar"}
+		err = candlebin.Write(out, period, candles, opts)
+	default:
+		log.Fatalf("unknown format %q", *formatFlag)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
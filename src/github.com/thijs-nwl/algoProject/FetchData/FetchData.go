@@ -1,43 +1,179 @@
+// FetchData downloads candles from an exchange into the datastore. Only
+// the parts of the requested range that are not stored yet are fetched.
+//
+// Usage:
+//
+//	FetchData -pair BTC_XMR,BTC_ETH -start "last 30d" -period 900
+//	FetchData -pair BTC_XMR -start 2017-12-01 -end 2018-01-20 -dry-run
+//	FetchData -pairs-file pairs.txt -workers 8 -start "last 7d"
+//	FetchData -exchange binance -pair BTC_ETH -start "last 30d"
+//	FetchData -pair BTC_XMR -start "last 1d" -trades -book
+//	FetchData -backend db -exchange kraken -pair BTC_ETH -start "last 7d"
+//
+// With more than one pair the downloads run as a pool of -workers jobs.
+// Every pair is reported as it finishes, followed by a summary, and the
+// exit status is non-zero if any pair failed.
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
-	"io/ioutil"
-	"net/http"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/binance"
+	"github.com/thijs-nwl/algoProject/csvfile"
+	"github.com/thijs-nwl/algoProject/datastore"
+	"github.com/thijs-nwl/algoProject/kraken"
+	"github.com/thijs-nwl/algoProject/market"
+	"github.com/thijs-nwl/algoProject/poloniex"
+	"github.com/thijs-nwl/algoProject/timespec"
 )
 
-var urlData = [4]string{"BTC", "XMR", "1512086400", "1512088400"}
+var (
+	pairFlag        = flag.String("pair", "BTC_XMR", "comma separated `pairs` to download, such as BTC_XMR,BTC_ETH")
+	pairsFileFlag   = flag.String("pairs-file", "", "`file` listing pairs to download, one or more per line; overrides -pair")
+	workersFlag     = flag.Int("workers", 4, "number of pairs downloaded at the same time")
+	startFlag       = flag.String("start", "last 1d", "start of the range: unix time, date, or duration before now such as \"last 30d\"")
+	endFlag         = flag.String("end", "now", "end of the range, in the same forms as -start")
+	periodFlag      = flag.Int("period", int(market.Period5m), "candle period in seconds: 300, 900, 1800, 7200, 14400 or 86400")
+	exchangeFlag    = flag.String("exchange", "poloniex", "candle `source`: "+strings.Join(sourceNames(), ", "))
+	csvDirFlag      = flag.String("csv-dir", ".", "`directory` of QUOTE_BASE_period.csv files for -exchange csv")
+	outFlag         = flag.String("out", "", "datastore `directory`; defaults to ../datastore for poloniex and ../datastore/<exchange> otherwise")
+	backendFlag     = flag.String("backend", "file", "candle storage: file, or db for one database of all exchanges in ../datastore")
+	dryRunFlag      = flag.Bool("dry-run", false, "print the requests that would be made without fetching anything")
+	tradesFlag      = flag.Bool("trades", false, "also store the trade history of the range (poloniex only)")
+	bookFlag        = flag.Bool("book", false, "also store an order book snapshot (poloniex only)")
+	depthFlag       = flag.Int("depth", 100, "order book levels per side for -book")
+	concurrencyFlag = flag.Int("concurrency", 4, "parallel requests per pair (poloniex only)")
+)
 
-func check(e error) {
-	if e != nil {
-		fmt.Println(e)
-	}
+// sources builds the candle source for each -exchange name.
+var sources = map[string]func() market.CandleSource{
+	"poloniex": func() market.CandleSource {
+		c := poloniex.NewClient()
+		c.Concurrency = *concurrencyFlag
+		return c
+	},
+	"binance": func() market.CandleSource { return binance.NewClient() },
+	"kraken":  func() market.CandleSource { return kraken.NewClient() },
+	"csv":     func() market.CandleSource { return &csvfile.Source{Dir: *csvDirFlag} },
 }
 
-func buildURL(first string, sec string, start string, end string) (string, string) {
-	return fmt.Sprintf("https://poloniex.com/public?command=returnChartData&currencyPair=%v_%v&start=%v&end=%v&period=300", first, sec, start, end),
-		fmt.Sprintf("../datastore/%v_%v_%v_%v_", first, sec, start, end)
+func sourceNames() []string {
+	var names []string
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func main() {
-	url, _ := buildURL(urlData[0], urlData[1], urlData[2], urlData[3])
-	err := getData(url)
-	if err == nil {
-		fmt.Printf("succes")
+	log.SetFlags(0)
+	flag.Parse()
+
+	pairs, err := parsePairs(*pairFlag)
+	if *pairsFileFlag != "" {
+		pairs, err = readPairsFile(*pairsFileFlag)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	now := time.Now().UTC()
+	start, err := timespec.Parse(*startFlag, now)
+	if err != nil {
+		log.Fatal(err)
+	}
+	end, err := timespec.ParseEnd(*endFlag, now)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if end.Before(start) {
+		log.Fatalf("end %v is before start %v", end, start)
+	}
+	period, err := parsePeriod(*periodFlag)
+	if err != nil {
+		log.Fatal(err)
 	}
-}
 
-func getData(url string) error {
-	res, err := http.Get(url)
-	check(err)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
-	body, err := ioutil.ReadAll(res.Body)
-	check(err)
-	b := []byte(body)
+	newSource, ok := sources[*exchangeFlag]
+	if !ok {
+		log.Fatalf("unknown exchange %q, want one of %v", *exchangeFlag, strings.Join(sourceNames(), ", "))
+	}
+	src := newSource()
 
-	_, path := buildURL(urlData[0], urlData[1], urlData[2], urlData[3])
-	err = ioutil.WriteFile(path, b, 0644)
-	check(err)
+	out := *outFlag
+	if out == "" {
+		out = "../datastore"
+		if *exchangeFlag != "poloniex" {
+			out = filepath.Join(out, *exchangeFlag)
+		}
+	}
+	if err := os.MkdirAll(out, 0755); err != nil {
+		log.Fatal(err)
+	}
+	store := datastore.New(out)
 
-	return err
+	// The database keys series by exchange, so all exchanges share the
+	// one in the datastore root.
+	dir := out
+	if *backendFlag == "db" && *outFlag == "" {
+		dir = "../datastore"
+	}
+	candles, err := datastore.Open(datastore.Config{Backend: *backendFlag, Dir: dir, Exchange: market.Exchange(*exchangeFlag)})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *dryRunFlag {
+		for _, pair := range pairs {
+			if err := plan(candles, src, pair, period, start, end); err != nil {
+				log.Fatal(err)
+			}
+		}
+		candles.Close()
+		return
+	}
+
+	jobs := runJobs(ctx, candles, store, src, pairs, period, start, end, *workersFlag)
+	if err := candles.Close(); err != nil {
+		log.Fatal(err)
+	}
+	if !summarize(jobs) {
+		os.Exit(1)
+	}
+}
+
+// plan prints the ranges Update would fetch for pair, and for Poloniex the
+// requests it would make.
+func plan(store datastore.Backend, src market.CandleSource, pair market.Pair, period market.Period, start, end time.Time) error {
+	stored, err := store.Load(pair, period, start, end)
+	if err != nil {
+		return err
+	}
+
+	gaps, err := datastore.Plan(store, pair, period, start, end)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%v: %d candles stored, %d ranges to fetch\n", pair, len(stored), len(gaps))
+	for _, g := range gaps {
+		fmt.Printf("  %v - %v\n", g.Start.UTC(), g.End.UTC())
+		if client, ok := src.(*poloniex.Client); ok {
+			for _, url := range client.ChartURLs(pair, g.Start, g.End, period) {
+				fmt.Println("    GET", url)
+			}
+		}
+	}
+	return nil
 }
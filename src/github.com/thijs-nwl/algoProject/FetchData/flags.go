@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// parsePairs reads a comma separated list of pairs in any form
+// market.ParsePair accepts, such as BTC_XMR or XMR/BTC.
+func parsePairs(s string) ([]market.Pair, error) {
+	var pairs []market.Pair
+	for _, f := range strings.Split(s, ",") {
+		p, err := market.ParsePair(f)
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs, nil
+}
+
+func parsePeriod(n int) (market.Period, error) {
+	p := market.Period(n)
+	if !p.Valid() {
+		return 0, fmt.Errorf("invalid period %d, want one of %v", n, market.Periods)
+	}
+	return p, nil
+}
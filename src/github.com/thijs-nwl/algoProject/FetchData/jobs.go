@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/datastore"
+	"github.com/thijs-nwl/algoProject/market"
+	"github.com/thijs-nwl/algoProject/poloniex"
+)
+
+// job is the download of one pair and its outcome.
+type job struct {
+	pair  market.Pair
+	added int
+	took  time.Duration
+	err   error
+}
+
+func (j job) String() string {
+	if j.err != nil {
+		return fmt.Sprintf("%v: FAILED after %v: %v", j.pair, j.took.Round(time.Millisecond), j.err)
+	}
+	return fmt.Sprintf("%v: ok, %d new candles in %v", j.pair, j.added, j.took.Round(time.Millisecond))
+}
+
+// runJobs updates the candles of every pair with at most workers downloads
+// at a time; trades and order books go to store. It prints each job as it
+// finishes and returns all of them in input order.
+func runJobs(ctx context.Context, candles datastore.Backend, store *datastore.Store, src market.CandleSource, pairs []market.Pair, period market.Period, start, end time.Time, workers int) []job {
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make([]job, len(pairs))
+	next := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				began := time.Now()
+				added, err := datastore.Update(ctx, candles, src, pairs[i], period, start, end)
+				if err == nil {
+					err = fetchTradesAndBook(ctx, store, src, pairs[i], start, end)
+				}
+				jobs[i] = job{pair: pairs[i], added: added, took: time.Since(began), err: err}
+
+				mu.Lock()
+				fmt.Println(jobs[i])
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range pairs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return jobs
+}
+
+// fetchTradesAndBook stores the trade history and an order book snapshot
+// of pair when -trades and -book ask for them.
+func fetchTradesAndBook(ctx context.Context, store *datastore.Store, src market.CandleSource, pair market.Pair, start, end time.Time) error {
+	if !*tradesFlag && !*bookFlag {
+		return nil
+	}
+	client, ok := src.(*poloniex.Client)
+	if !ok {
+		return fmt.Errorf("-trades and -book are only supported for poloniex")
+	}
+
+	if *tradesFlag {
+		trades, err := client.TradeHistory(ctx, pair, start, end)
+		if err != nil {
+			return err
+		}
+		if err := store.SaveTrades(pair, trades); err != nil {
+			return err
+		}
+	}
+	if *bookFlag {
+		book, err := client.OrderBook(ctx, pair, *depthFlag)
+		if err != nil {
+			return err
+		}
+		if err := store.SaveOrderBook(pair, book); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// summarize prints how many jobs failed and which, and reports whether all
+// of them succeeded.
+func summarize(jobs []job) bool {
+	var failed []string
+	for _, j := range jobs {
+		if j.err != nil {
+			failed = append(failed, j.pair.String())
+		}
+	}
+
+	fmt.Printf("%d of %d pairs downloaded", len(jobs)-len(failed), len(jobs))
+	if len(failed) > 0 {
+		fmt.Printf(", failed: %v", strings.Join(failed, ", "))
+	}
+	fmt.Println()
+	return len(failed) == 0
+}
+
+// readPairsFile reads pairs from a file with one or more comma separated
+// pairs per line. Blank lines and lines starting with # are skipped.
+func readPairsFile(path string) ([]market.Pair, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var pairs []market.Pair
+	for n, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p, err := parsePairs(line)
+		if err != nil {
+			return nil, fmt.Errorf("%v:%d: %v", path, n+1, err)
+		}
+		pairs = append(pairs, p...)
+	}
+	return pairs, nil
+}
@@ -0,0 +1,82 @@
+// ImportData adds candles from CSV files to the datastore, so OHLCV data
+// from other sources can be used like downloaded data. The CSV needs a
+// header with at least date, open, high, low and close columns.
+//
+// Usage:
+//
+//	ImportData -pair BTC_XMR -period 300 xmr_history.csv
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/thijs-nwl/algoProject/csvfile"
+	"github.com/thijs-nwl/algoProject/datastore"
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+var (
+	dirFlag      = flag.String("dir", "../datastore", "datastore `directory`")
+	backendFlag  = flag.String("backend", "file", "candle storage: file or db")
+	exchangeFlag = flag.String("exchange", "poloniex", "exchange the series is keyed by in -backend db")
+	pairFlag     = flag.String("pair", "", "pair the candles belong to, such as BTC_XMR")
+	periodFlag   = flag.Int("period", int(market.Period5m), "candle period in seconds")
+	strictFlag   = flag.Bool("strict", false, "refuse files with gaps, duplicates or malformed candles")
+)
+
+func main() {
+	log.SetFlags(0)
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("usage: ImportData -pair BTC_XMR [-period 300] file.csv...")
+	}
+
+	pair, err := market.ParsePair(*pairFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	period := market.Period(*periodFlag)
+	if !period.Valid() {
+		log.Fatalf("invalid period %d, want one of %v", *periodFlag, market.Periods)
+	}
+	store, err := datastore.Open(datastore.Config{Backend: *backendFlag, Dir: *dirFlag, Exchange: market.Exchange(*exchangeFlag)})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer store.Close()
+
+	for _, path := range flag.Args() {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		candles, err := csvfile.Read(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("%v: %v", path, err)
+		}
+
+		issues, err := market.Validate(candles, period)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, issue := range issues {
+			fmt.Fprintf(os.Stderr, "%v: %v\n", path, issue)
+		}
+		if *strictFlag && len(issues) > 0 {
+			log.Fatalf("%v: %d issues, not imported", path, len(issues))
+		}
+
+		repaired, err := market.Repair(candles, period, market.Drop)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := store.Save(pair, period, repaired); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%v: imported %d %v candles\n", path, len(repaired), pair)
+	}
+}
@@ -0,0 +1,81 @@
+// PaperTrade runs the moving average crossover strategy forward on live
+// Poloniex candles with a simulated account. The account is saved after
+// every candle, so stopping and starting PaperTrade with the same -state
+// file resumes the run. With -backend the candles are also kept in the
+// datastore.
+//
+// Usage:
+//
+//	PaperTrade -pair BTC_XMR -period 300 -state btc_xmr.json
+//	PaperTrade -pair BTC_XMR -backend db -dir ../datastore
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/backtest"
+	"github.com/thijs-nwl/algoProject/datastore"
+	"github.com/thijs-nwl/algoProject/market"
+	"github.com/thijs-nwl/algoProject/paper"
+	"github.com/thijs-nwl/algoProject/poloniex"
+	"github.com/thijs-nwl/algoProject/strategies"
+)
+
+var (
+	pairFlag     = flag.String("pair", "BTC_XMR", "pair to trade")
+	periodFlag   = flag.Int("period", int(market.Period5m), "candle period in seconds")
+	stateFlag    = flag.String("state", "paper.json", "`file` the account is kept in")
+	dirFlag      = flag.String("dir", "../datastore", "datastore `directory` for -backend")
+	backendFlag  = flag.String("backend", "", "candle storage to keep the candles in: file or db; none when empty")
+	fastFlag     = flag.Int("fast", 12, "candles in the fast moving average")
+	slowFlag     = flag.Int("slow", 48, "candles in the slow moving average")
+	cashFlag     = flag.Float64("cash", 1, "starting balance in the quote currency, for a new state file")
+	feeFlag      = flag.Float64("fee", 0.0025, "fee as a fraction of the traded value")
+	slippageFlag = flag.Float64("slippage", 0.001, "slippage as a fraction of the price")
+)
+
+func main() {
+	flag.Parse()
+
+	pair, err := market.ParsePair(*pairFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	period := market.Period(*periodFlag)
+	if !period.Valid() {
+		log.Fatalf("invalid period %d, want one of %v", *periodFlag, market.Periods)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var store datastore.Backend
+	if *backendFlag != "" {
+		store, err = datastore.Open(datastore.Config{Backend: *backendFlag, Dir: *dirFlag, Exchange: market.Poloniex})
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer store.Close()
+	}
+
+	t := &paper.Trader{
+		Source:    poloniex.NewClient(),
+		Pair:      pair,
+		Period:    period,
+		Store:     store,
+		Strategy:  strategies.NewSMACross(*fastFlag, *slowFlag),
+		Config:    backtest.Config{Cash: *cashFlag, Fee: *feeFlag, Slippage: *slippageFlag},
+		StatePath: *stateFlag,
+		Warmup:    *slowFlag,
+		Delay:     10 * time.Second,
+		Log:       log.New(os.Stderr, "", log.LstdFlags),
+	}
+	if err := t.Run(ctx); err != nil && err != context.Canceled {
+		log.Fatal(err)
+	}
+}
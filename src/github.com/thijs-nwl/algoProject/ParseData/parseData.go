@@ -1,35 +1,44 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"math"
-)
-
-type Candle struct {
-	Date   int32
-	High   float32
-	Low    float32
-	Open   float64
-	Close  float64
-	Change int
-}
+	"log"
+	"time"
 
-var candles []Candle
+	"github.com/thijs-nwl/algoProject/datastore"
+	"github.com/thijs-nwl/algoProject/market"
+)
 
-func read() {
-	b, err := ioutil.ReadFile("../datastore/BTC_XMR_1512086400_1512087400_")
+var pair = market.MustParsePair("BTC_XMR")
 
-	err = json.Unmarshal(b, &candles)
+func main() {
+	stream, err := datastore.New("../datastore").Stream(pair, market.Period5m, time.Unix(1512086400, 0), time.Unix(1512087400, 0))
 	if err != nil {
-		fmt.Println("error:", err)
+		log.Fatal(err)
 	}
-}
+	defer stream.Close()
 
-func main() {
-	read()
-	fmt.Println(candles[0])
-	Change := math.Dim(candles[0].Open, candles[0].Close)
-	fmt.Println(Change)
+	v, err := market.NewValidator(market.Period5m)
+	if err != nil {
+		log.Fatal(err)
+	}
+	n := 0
+	for stream.Next() {
+		c := stream.Candle()
+		for _, issue := range v.Check(c) {
+			fmt.Println(issue)
+		}
+		if n == 0 {
+			fmt.Println(c.Time(), c)
+			fmt.Printf("change %v (%.2f%%), range %v, body %v\n", c.Change(), c.PercentChange(), c.Range(), c.Body())
+		}
+		n++
+	}
+	if err := stream.Err(); err != nil {
+		log.Fatal(err)
+	}
+	if n == 0 {
+		log.Fatal("no candles stored")
+	}
+	fmt.Printf("%d candles\n", n)
 }
@@ -0,0 +1,243 @@
+// QueryData inspects the candles in the datastore.
+//
+// Usage:
+//
+//	QueryData list
+//	QueryData -pair BTC_XMR -start 2017-12-01 -end "2017-12-01 12:00" show
+//	QueryData -pair BTC_XMR -where "change>2%,volume>10" show
+//	QueryData -pair BTC_XMR -period 86400 stats
+//	QueryData -pair BTC_XMR -window 48 returns
+//	QueryData -pair BTC_XMR -with BTC_ETH -window 288 corr
+//
+// list prints every stored series with its range and candle count. show
+// prints the candles of -pair between -start and -end that meet -where,
+// and stats summarizes the same candles and the distribution of their
+// returns. returns prints the returns, rolling volatility and drawdown of
+// every candle, and corr the rolling correlation of the log returns of
+// -pair and -with; both ignore -where. Returns across a gap in the stored
+// series are left out. Flags go before the command.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/datastore"
+	"github.com/thijs-nwl/algoProject/market"
+	"github.com/thijs-nwl/algoProject/stats"
+	"github.com/thijs-nwl/algoProject/timespec"
+)
+
+var (
+	dirFlag      = flag.String("dir", "../datastore", "datastore `directory`")
+	backendFlag  = flag.String("backend", "file", "candle storage: file or db")
+	exchangeFlag = flag.String("exchange", "poloniex", "exchange the series is keyed by in -backend db")
+	pairFlag     = flag.String("pair", "BTC_XMR", "pair to query")
+	periodFlag   = flag.Int("period", int(market.Period5m), "candle period in seconds")
+	startFlag    = flag.String("start", "", "first `time` to query, as a Unix time, 2017-12-01 or 2017-12-01 15:04; defaults to the start of the data")
+	endFlag      = flag.String("end", "", "last `time` to query, in the same forms as -start; a date alone includes the whole day; defaults to the end of the data")
+	whereFlag    = flag.String("where", "", "comma separated `conditions` candles must meet, such as \"change>2%,volume>10\"")
+	windowFlag   = flag.Int("window", 20, "number of returns in the rolling volatility and correlation")
+	withFlag     = flag.String("with", "", "second `pair` for corr")
+)
+
+func main() {
+	log.SetFlags(0)
+	flag.Parse()
+	if flag.NArg() != 1 {
+		log.Fatal("usage: QueryData [flags] list|show|stats|returns|corr")
+	}
+
+	pair, err := market.ParsePair(*pairFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	period := market.Period(*periodFlag)
+	if !period.Valid() {
+		log.Fatalf("invalid period %d, want one of %v", *periodFlag, market.Periods)
+	}
+
+	store, err := datastore.Open(datastore.Config{Backend: *backendFlag, Dir: *dirFlag, Exchange: market.Exchange(*exchangeFlag)})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer store.Close()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	switch cmd := flag.Arg(0); cmd {
+	case "list":
+		err = list(w, store)
+	case "show", "stats":
+		var candles []market.Candle
+		candles, err = query(store, pair, period)
+		if err != nil {
+			break
+		}
+		if cmd == "show" {
+			show(w, candles)
+		} else {
+			summarize(w, pair, period, candles)
+		}
+	case "returns":
+		var candles []market.Candle
+		candles, err = load(store, pair, period)
+		if err == nil {
+			returns(w, period, candles, *windowFlag)
+		}
+	case "corr":
+		err = corr(w, store, pair, period)
+	default:
+		err = fmt.Errorf("unknown command %q, want list, show, stats, returns or corr", cmd)
+	}
+	if err != nil {
+		w.Flush()
+		store.Close()
+		log.Fatal(err)
+	}
+}
+
+// load loads the candles of pair at period between -start and -end.
+func load(store datastore.Backend, pair market.Pair, period market.Period) ([]market.Candle, error) {
+	start, end, err := timespec.Range(*startFlag, *endFlag, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	candles, err := store.Load(pair, period, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if len(candles) == 0 {
+		return nil, fmt.Errorf("no %v candles of %v seconds stored", pair, period)
+	}
+	return candles, nil
+}
+
+// query loads the candles of pair at period selected by -start, -end and
+// -where.
+func query(store datastore.Backend, pair market.Pair, period market.Period) ([]market.Candle, error) {
+	conds, err := parseWhere(*whereFlag)
+	if err != nil {
+		return nil, err
+	}
+	candles, err := load(store, pair, period)
+	if err != nil {
+		return nil, err
+	}
+	var selected []market.Candle
+	for _, c := range candles {
+		if match(c, conds) {
+			selected = append(selected, c)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no %v candles of %v seconds match", pair, period)
+	}
+	return selected, nil
+}
+
+func list(w *tabwriter.Writer, store datastore.Backend) error {
+	series, err := store.Series()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "pair\tperiod\tstart\tend\tcandles")
+	for _, s := range series {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%d\n", s.Pair, s.Period, s.Start.UTC().Format(time.RFC3339), s.End.UTC().Format(time.RFC3339), s.Count)
+	}
+	return nil
+}
+
+func show(w *tabwriter.Writer, candles []market.Candle) {
+	fmt.Fprintln(w, "time\topen\thigh\tlow\tclose\tvolume\tchange")
+	for _, c := range candles {
+		fmt.Fprintf(w, "%v\t%.8f\t%.8f\t%.8f\t%.8f\t%.4f\t%.2f%%\n",
+			c.Time().Format(time.RFC3339), c.Open, c.High, c.Low, c.Close, c.Volume, c.PercentChange())
+	}
+}
+
+// summarize prints the range and volume of candles and the distribution
+// of the log returns between successive candles. Candles more than one
+// period apart, as left by gaps or -where, do not count as successive.
+func summarize(w *tabwriter.Writer, pair market.Pair, period market.Period, candles []market.Candle) {
+	low, high := candles[0], candles[0]
+	var sum, volume, quoteVolume float64
+	for _, c := range candles {
+		if c.Close < low.Close {
+			low = c
+		}
+		if c.Close > high.Close {
+			high = c
+		}
+		sum += c.Close
+		volume += c.Volume
+		quoteVolume += c.QuoteVolume
+	}
+	logReturns := stats.MaskGaps(stats.LogReturns(candles), candles, period)
+
+	first, last := candles[0], candles[len(candles)-1]
+	fmt.Fprintf(w, "candles\t%d\n", len(candles))
+	fmt.Fprintf(w, "from\t%v\n", first.Time().Format(time.RFC3339))
+	fmt.Fprintf(w, "to\t%v\n", last.Time().Format(time.RFC3339))
+	fmt.Fprintf(w, "min close\t%.8f at %v\n", low.Close, low.Time().Format(time.RFC3339))
+	fmt.Fprintf(w, "max close\t%.8f at %v\n", high.Close, high.Time().Format(time.RFC3339))
+	fmt.Fprintf(w, "mean close\t%.8f\n", sum/float64(len(candles)))
+	fmt.Fprintf(w, "volume\t%.4f %v\n", volume, pair.Quote())
+	fmt.Fprintf(w, "quote volume\t%.4f %v\n", quoteVolume, pair.Base())
+
+	vol := stats.StdDev(logReturns)
+	fmt.Fprintf(w, "volatility\t%.4f%% per period, %.2f%% annualized\n", vol*100, stats.Annualize(vol, period)*100)
+	fmt.Fprintf(w, "skewness\t%.4f\n", stats.Skewness(logReturns))
+	fmt.Fprintf(w, "excess kurtosis\t%.4f\n", stats.Kurtosis(logReturns))
+	fmt.Fprintf(w, "autocorrelation\t%.4f at lag 1\n", stats.Autocorrelation(logReturns, 1))
+	fmt.Fprintf(w, "max drawdown\t%.2f%%\n", stats.MaxDrawdown(stats.Drawdowns(candles))*100)
+}
+
+// returns prints the returns of candles with their rolling volatility and
+// drawdown. Returns across a gap in the series are NaN.
+func returns(w *tabwriter.Writer, period market.Period, candles []market.Candle, window int) {
+	simple := stats.MaskGaps(stats.SimpleReturns(candles), candles, period)
+	logs := stats.MaskGaps(stats.LogReturns(candles), candles, period)
+	vol := stats.RollingVolatility(logs, window)
+	dd := stats.Drawdowns(candles)
+	fmt.Fprintln(w, "time\tclose\treturn\tlog return\tvolatility\tdrawdown")
+	for i, c := range candles {
+		fmt.Fprintf(w, "%v\t%.8f\t%.4f%%\t%.6f\t%.4f%%\t%.2f%%\n",
+			c.Time().Format(time.RFC3339), c.Close, simple[i]*100, logs[i], vol[i]*100, dd[i]*100)
+	}
+}
+
+// corr prints the rolling correlation of the log returns of pair and
+// -with over the candles both have, followed by the correlation over the
+// whole range. -where does not apply: correlating only the candles that
+// meet it would pair returns across the candles left out.
+func corr(w *tabwriter.Writer, store datastore.Backend, pair market.Pair, period market.Period) error {
+	with, err := market.ParsePair(*withFlag)
+	if err != nil {
+		return fmt.Errorf("-with: %v", err)
+	}
+	a, err := load(store, pair, period)
+	if err != nil {
+		return err
+	}
+	b, err := load(store, with, period)
+	if err != nil {
+		return err
+	}
+
+	a, b = stats.Align(a, b)
+	ra := stats.MaskGaps(stats.LogReturns(a), a, period)
+	rb := stats.MaskGaps(stats.LogReturns(b), b, period)
+	rolling := stats.RollingCorrelation(ra, rb, *windowFlag)
+	fmt.Fprintf(w, "time\t%v\t%v\tcorrelation\n", pair, with)
+	for i, c := range a {
+		fmt.Fprintf(w, "%v\t%.8f\t%.8f\t%.4f\n", c.Time().Format(time.RFC3339), c.Close, b[i].Close, rolling[i])
+	}
+	fmt.Fprintf(w, "overall\t\t\t%.4f\n", stats.Correlation(ra, rb))
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// fields are the candle values -where can compare. change is the percent
+// change from open to close; the others are in the units of the candle.
+var fields = map[string]func(market.Candle) float64{
+	"open":        func(c market.Candle) float64 { return c.Open },
+	"high":        func(c market.Candle) float64 { return c.High },
+	"low":         func(c market.Candle) float64 { return c.Low },
+	"close":       func(c market.Candle) float64 { return c.Close },
+	"volume":      func(c market.Candle) float64 { return c.Volume },
+	"quotevolume": func(c market.Candle) float64 { return c.QuoteVolume },
+	"change":      market.Candle.PercentChange,
+	"range":       market.Candle.Range,
+	"body":        market.Candle.Body,
+}
+
+// operators are tried in order, so the two character ones match first.
+var operators = []string{">=", "<=", "!=", ">", "<", "="}
+
+// condition is one comparison of -where, such as change>2%.
+type condition struct {
+	field string
+	op    string
+	value float64
+}
+
+// parseWhere reads comma separated conditions such as "change>2%,volume>10".
+func parseWhere(s string) ([]condition, error) {
+	var conds []condition
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		c, err := parseCondition(part)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, c)
+	}
+	return conds, nil
+}
+
+func parseCondition(s string) (condition, error) {
+	for _, op := range operators {
+		i := strings.Index(s, op)
+		if i < 0 {
+			continue
+		}
+		field := strings.ToLower(strings.TrimSpace(s[:i]))
+		if _, ok := fields[field]; !ok {
+			return condition{}, fmt.Errorf("unknown field %q in %q, want one of %v", field, s, strings.Join(fieldNames(), ", "))
+		}
+		value := strings.TrimSpace(s[i+len(op):])
+		if field == "change" {
+			value = strings.TrimSuffix(value, "%")
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return condition{}, fmt.Errorf("invalid value in %q", s)
+		}
+		return condition{field: field, op: op, value: v}, nil
+	}
+	return condition{}, fmt.Errorf("no comparison in %q", s)
+}
+
+func fieldNames() []string {
+	var names []string
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// match reports whether c meets every condition.
+func match(c market.Candle, conds []condition) bool {
+	for _, cond := range conds {
+		v := fields[cond.field](c)
+		var ok bool
+		switch cond.op {
+		case ">=":
+			ok = v >= cond.value
+		case "<=":
+			ok = v <= cond.value
+		case "!=":
+			ok = v != cond.value
+		case ">":
+			ok = v > cond.value
+		case "<":
+			ok = v < cond.value
+		case "=":
+			ok = v == cond.value
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
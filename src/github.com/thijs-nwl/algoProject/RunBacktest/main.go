@@ -0,0 +1,119 @@
+// RunBacktest runs a moving average crossover strategy over candles from the
+// datastore and prints a performance report.
+//
+// Usage:
+//
+//	RunBacktest -pair BTC_XMR -fast 12 -slow 48 -fee 0.0025
+//	RunBacktest -report json -o report.json
+//	RunBacktest -books
+//
+// With -books the slippage of each fill comes from the order book
+// snapshots FetchData -book stored, where there is one taken at most
+// -book-age before the fill, instead of from -slippage.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/backtest"
+	"github.com/thijs-nwl/algoProject/datastore"
+	"github.com/thijs-nwl/algoProject/market"
+	"github.com/thijs-nwl/algoProject/strategies"
+	"github.com/thijs-nwl/algoProject/timespec"
+)
+
+var (
+	dirFlag      = flag.String("dir", "../datastore", "datastore `directory`")
+	backendFlag  = flag.String("backend", "file", "candle storage: file or db")
+	exchangeFlag = flag.String("exchange", "poloniex", "exchange the series is keyed by in -backend db")
+	pairFlag     = flag.String("pair", "BTC_XMR", "pair to test on")
+	periodFlag   = flag.Int("period", int(market.Period5m), "candle period in seconds")
+	startFlag    = flag.String("start", "", "first `time` to test, as a Unix time, 2017-12-01 or 2017-12-01 15:04; defaults to the start of the data")
+	endFlag      = flag.String("end", "", "last `time` to test, in the same forms as -start; a date alone includes the whole day; defaults to the end of the data")
+	fastFlag     = flag.Int("fast", 12, "candles in the fast moving average")
+	slowFlag     = flag.Int("slow", 48, "candles in the slow moving average")
+	cashFlag     = flag.Float64("cash", 1, "starting balance in the quote currency")
+	feeFlag      = flag.Float64("fee", 0.0025, "fee as a fraction of the traded value")
+	slippageFlag = flag.Float64("slippage", 0.001, "slippage as a fraction of the price")
+	booksFlag    = flag.Bool("books", false, "take slippage from the stored order book snapshots of -pair in -dir")
+	bookAgeFlag  = flag.Duration("book-age", time.Hour, "oldest snapshot -books uses for a fill; 0 uses any")
+	tradesFlag   = flag.Bool("trades", false, "print every trade before the report")
+	reportFlag   = flag.String("report", "table", "report `format`: table, json or csv")
+	outFlag      = flag.String("o", "", "write the report to `file` instead of standard output")
+)
+
+func main() {
+	log.SetFlags(0)
+	flag.Parse()
+
+	pair, err := market.ParsePair(*pairFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	start, end, err := timespec.Range(*startFlag, *endFlag, time.Now())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	store, err := datastore.Open(datastore.Config{Backend: *backendFlag, Dir: *dirFlag, Exchange: market.Exchange(*exchangeFlag)})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer store.Close()
+	stream, err := store.Stream(pair, market.Period(*periodFlag), start, end)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer stream.Close()
+
+	cfg := backtest.Config{Cash: *cashFlag, Fee: *feeFlag, Slippage: *slippageFlag}
+	if *booksFlag {
+		books, err := datastore.New(*dirFlag).LoadOrderBooks(pair, time.Unix(0, 0), end)
+		if err != nil {
+			log.Fatal(err)
+		}
+		cfg.Books = books
+		cfg.MaxBookAge = *bookAgeFlag
+	}
+	res, err := backtest.RunIterator(stream, strategies.NewSMACross(*fastFlag, *slowFlag), cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *tradesFlag {
+		for _, t := range res.Trades {
+			fmt.Println(t)
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if *outFlag != "" {
+		f, err := os.Create(*outFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	report := backtest.NewReport(res)
+	switch *reportFlag {
+	case "table":
+		fmt.Fprintf(out, "%v, %d candles of %v seconds\n", pair, len(res.Equity), *periodFlag)
+		err = report.WriteTable(out)
+	case "json":
+		err = report.WriteJSON(out)
+	case "csv":
+		err = report.WriteCSV(out)
+	default:
+		log.Fatalf("unknown report format %q", *reportFlag)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
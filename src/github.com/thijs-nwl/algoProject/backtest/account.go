@@ -0,0 +1,126 @@
+package backtest
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// Config sets the starting capital and trading costs of an Account.
+type Config struct {
+	// Cash is the starting balance in the quote currency.
+	Cash float64
+	// Fee is charged on the traded value, as a fraction: 0.0025 is 0.25%.
+	Fee float64
+	// Slippage moves every fill against the order, as a fraction of the
+	// price.
+	Slippage float64
+	// Books, when set, replaces Slippage with what the order would cost
+	// walking the latest snapshot taken at or before the fill. Slippage
+	// still applies before the first snapshot, when the latest snapshot is
+	// older than MaxBookAge and when a snapshot is not deep enough for the
+	// order.
+	Books Books
+	// MaxBookAge is how long before a fill a snapshot may have been taken
+	// to be used for it. Zero accepts snapshots of any age.
+	MaxBookAge time.Duration
+}
+
+// Books are order book snapshots ordered by date, as
+// datastore.LoadOrderBooks returns them.
+type Books []market.OrderBook
+
+// At returns the latest snapshot taken at or before t. It reports false
+// when there is none, or when maxAge is positive and the snapshot was
+// taken more than maxAge before t.
+func (b Books) At(t time.Time, maxAge time.Duration) (*market.OrderBook, bool) {
+	i := sort.Search(len(b), func(i int) bool { return b[i].Date > t.Unix() })
+	if i == 0 {
+		return nil, false
+	}
+	book := &b[i-1]
+	if maxAge > 0 && t.Sub(time.Unix(book.Date, 0)) > maxAge {
+		return nil, false
+	}
+	return book, true
+}
+
+// Portfolio is what an account holds: Cash in the quote currency and
+// Position in the base currency.
+type Portfolio struct {
+	Cash     float64 `json:"cash"`
+	Position float64 `json:"position"`
+}
+
+// Equity values the portfolio at price.
+func (p Portfolio) Equity(price float64) float64 {
+	return p.Cash + p.Position*price
+}
+
+// Account simulates the fills of a long-only account. Orders larger than
+// the account can afford, or sells larger than the position, are reduced
+// to what is possible.
+type Account struct {
+	Config    Config
+	Portfolio Portfolio
+	Trades    []Trade
+}
+
+// NewAccount returns an account holding cfg.Cash.
+func NewAccount(cfg Config) *Account {
+	return &Account{Config: cfg, Portfolio: Portfolio{Cash: cfg.Cash}}
+}
+
+// Fill executes o at the open of c and records the trade. It reports false
+// when nothing could be traded.
+func (a *Account) Fill(o Order, c market.Candle) (Trade, bool) {
+	slippage := a.slippage(o, c.Time())
+	slip := 1 + slippage
+	if o.Side == market.Sell {
+		slip = 1 - slippage
+	}
+	price := c.Open * slip
+	if price <= 0 {
+		return Trade{}, false
+	}
+
+	amount := o.Amount
+	switch o.Side {
+	case market.Buy:
+		amount = math.Min(amount, a.Portfolio.Cash/(price*(1+a.Config.Fee)))
+	case market.Sell:
+		amount = math.Min(amount, a.Portfolio.Position)
+	}
+	if amount <= 0 {
+		return Trade{}, false
+	}
+
+	value := amount * price
+	fee := value * a.Config.Fee
+	switch o.Side {
+	case market.Buy:
+		a.Portfolio.Cash -= value + fee
+		a.Portfolio.Position += amount
+	case market.Sell:
+		a.Portfolio.Cash += value - fee
+		a.Portfolio.Position -= amount
+	}
+
+	t := Trade{Time: c.Time(), Side: o.Side, Amount: amount, Price: price, Fee: fee}
+	a.Trades = append(a.Trades, t)
+	return t, true
+}
+
+// slippage returns the slippage of o filled at t: from the order book at
+// t for the amount ordered when there is a recent one deep enough, and
+// Config.Slippage otherwise.
+func (a *Account) slippage(o Order, t time.Time) float64 {
+	if book, ok := a.Config.Books.At(t, a.Config.MaxBookAge); ok {
+		if s, ok := book.Slippage(o.Side, o.Amount); ok {
+			return s
+		}
+	}
+	return a.Config.Slippage
+}
@@ -0,0 +1,52 @@
+package backtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+func TestBooksAt(t *testing.T) {
+	books := Books{{Date: 1000}, {Date: 2000}}
+	tests := []struct {
+		at     int64
+		maxAge time.Duration
+		want   int64 // date of the snapshot, 0 for none
+	}{
+		{999, 0, 0},
+		{1000, 0, 1000},
+		{1999, 0, 1000},
+		{5000, 0, 2000},
+		{2600, 10 * time.Minute, 2000},
+		{2601, 10 * time.Minute, 0},
+	}
+	for _, tt := range tests {
+		book, ok := books.At(time.Unix(tt.at, 0), tt.maxAge)
+		var got int64
+		if ok {
+			got = book.Date
+		}
+		if got != tt.want {
+			t.Errorf("At(%d, %v) = snapshot %d, want %d", tt.at, tt.maxAge, got, tt.want)
+		}
+	}
+}
+
+func TestStaleBookSlippage(t *testing.T) {
+	book := market.OrderBook{
+		Date: 0,
+		Asks: []market.Level{{Price: 102, Amount: 10}},
+		Bids: []market.Level{{Price: 98, Amount: 10}},
+	}
+	a := NewAccount(Config{Cash: 1000, Slippage: 0.001, Books: Books{book}, MaxBookAge: time.Hour})
+	buy := Order{Side: market.Buy, Amount: 1}
+
+	fresh := a.slippage(buy, time.Unix(0, 0).Add(time.Hour))
+	if fresh == a.Config.Slippage {
+		t.Errorf("slippage within MaxBookAge = %v, want it from the book", fresh)
+	}
+	if stale := a.slippage(buy, time.Unix(0, 0).Add(time.Hour+time.Second)); stale != a.Config.Slippage {
+		t.Errorf("slippage past MaxBookAge = %v, want Config.Slippage %v", stale, a.Config.Slippage)
+	}
+}
@@ -0,0 +1,29 @@
+package backtest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// Order is a market order for Amount of the base currency. It is filled
+// at the open of the candle after the one it was placed on.
+type Order struct {
+	Side   market.Side
+	Amount float64
+}
+
+// Trade is a filled order. Price includes slippage and Fee is paid in the
+// quote currency.
+type Trade struct {
+	Time   time.Time
+	Side   market.Side
+	Amount float64
+	Price  float64
+	Fee    float64
+}
+
+func (t Trade) String() string {
+	return fmt.Sprintf("%v %v %.8f @ %.8f fee %.8f", t.Time.Format("2006-01-02 15:04"), t.Side, t.Amount, t.Price, t.Fee)
+}
@@ -0,0 +1,234 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// Report summarizes the performance of a backtest. Returns, drawdowns,
+// win rate and exposure are fractions; Sharpe and Sortino are annualized
+// from the per-candle returns with a risk free rate of zero.
+type Report struct {
+	Start               time.Time `json:"start"`
+	End                 time.Time `json:"end"`
+	StartEquity         float64   `json:"startEquity"`
+	EndEquity           float64   `json:"endEquity"`
+	TotalReturn         float64   `json:"totalReturn"`
+	CAGR                float64   `json:"cagr"`
+	MaxDrawdown         float64   `json:"maxDrawdown"`
+	MaxDrawdownDuration Duration  `json:"maxDrawdownDuration"`
+	Sharpe              float64   `json:"sharpe"`
+	Sortino             float64   `json:"sortino"`
+	RoundTrips          int       `json:"roundTrips"`
+	WinRate             float64   `json:"winRate"`
+	ProfitFactor        float64   `json:"profitFactor"`
+	Exposure            float64   `json:"exposure"`
+	AverageTrade        float64   `json:"averageTrade"`
+	Fees                float64   `json:"fees"`
+}
+
+// Duration is a time.Duration that reads as "72h0m0s" in JSON.
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// NewReport computes the performance of res. A round trip runs from
+// opening a position to closing it completely; one still open at the end
+// is not counted in the trade statistics.
+func NewReport(res *Result) Report {
+	var r Report
+	if len(res.Equity) == 0 {
+		return r
+	}
+
+	first, last := res.Equity[0], res.Equity[len(res.Equity)-1]
+	r.Start, r.End = first.Time, last.Time
+	r.StartEquity, r.EndEquity = res.Config.Cash, last.Equity
+	if r.StartEquity > 0 {
+		r.TotalReturn = r.EndEquity/r.StartEquity - 1
+	}
+	if years := r.End.Sub(r.Start).Hours() / 24 / 365.25; years > 0 && r.StartEquity > 0 {
+		r.CAGR = math.Pow(r.EndEquity/r.StartEquity, 1/years) - 1
+	}
+
+	r.drawdown(res.Equity)
+	r.ratios(res.Equity)
+	r.roundTrips(res.Trades)
+	return r
+}
+
+func (r *Report) drawdown(equity []Point) {
+	peak, peakTime := equity[0].Equity, equity[0].Time
+	exposed := 0
+	for _, p := range equity {
+		if p.Position > 0 {
+			exposed++
+		}
+		if p.Equity >= peak {
+			peak, peakTime = p.Equity, p.Time
+			continue
+		}
+		if dd := 1 - p.Equity/peak; dd > r.MaxDrawdown {
+			r.MaxDrawdown = dd
+		}
+		if d := Duration(p.Time.Sub(peakTime)); d > r.MaxDrawdownDuration {
+			r.MaxDrawdownDuration = d
+		}
+	}
+	r.Exposure = float64(exposed) / float64(len(equity))
+}
+
+func (r *Report) ratios(equity []Point) {
+	if len(equity) < 3 {
+		return
+	}
+
+	var returns []float64
+	for i := 1; i < len(equity); i++ {
+		if prev := equity[i-1].Equity; prev > 0 {
+			returns = append(returns, equity[i].Equity/prev-1)
+		}
+	}
+	if len(returns) == 0 {
+		return
+	}
+
+	var sum, sq, down float64
+	for _, x := range returns {
+		sum += x
+	}
+	mean := sum / float64(len(returns))
+	for _, x := range returns {
+		sq += (x - mean) * (x - mean)
+		if x < 0 {
+			down += x * x
+		}
+	}
+	std := math.Sqrt(sq / float64(len(returns)))
+	downside := math.Sqrt(down / float64(len(returns)))
+
+	step := equity[1].Time.Sub(equity[0].Time)
+	if step <= 0 {
+		return
+	}
+	annualize := math.Sqrt(365.25 * 24 * float64(time.Hour) / float64(step))
+	if std > 0 {
+		r.Sharpe = mean / std * annualize
+	}
+	if downside > 0 {
+		r.Sortino = mean / downside * annualize
+	}
+}
+
+func (r *Report) roundTrips(trades []Trade) {
+	var position, pnl, won, lost, total float64
+	wins := 0
+	for _, t := range trades {
+		r.Fees += t.Fee
+		value := t.Amount * t.Price
+		if t.Side == market.Buy {
+			position += t.Amount
+			pnl -= value + t.Fee
+			continue
+		}
+
+		position -= t.Amount
+		pnl += value - t.Fee
+		if position > 0 {
+			continue
+		}
+
+		r.RoundTrips++
+		total += pnl
+		if pnl > 0 {
+			wins++
+			won += pnl
+		} else {
+			lost -= pnl
+		}
+		pnl = 0
+	}
+
+	if r.RoundTrips > 0 {
+		r.WinRate = float64(wins) / float64(r.RoundTrips)
+		r.AverageTrade = total / float64(r.RoundTrips)
+	}
+	switch {
+	case lost > 0:
+		r.ProfitFactor = won / lost
+	case won > 0:
+		r.ProfitFactor = math.Inf(1)
+	}
+}
+
+// rows returns the report as metric name and formatted value pairs.
+func (r Report) rows() [][2]string {
+	pct := func(f float64) string { return fmt.Sprintf("%.2f%%", f*100) }
+	num := func(f float64) string { return fmt.Sprintf("%.8g", f) }
+	return [][2]string{
+		{"start", r.Start.Format(time.RFC3339)},
+		{"end", r.End.Format(time.RFC3339)},
+		{"start equity", num(r.StartEquity)},
+		{"end equity", num(r.EndEquity)},
+		{"total return", pct(r.TotalReturn)},
+		{"CAGR", pct(r.CAGR)},
+		{"max drawdown", pct(r.MaxDrawdown)},
+		{"max drawdown duration", time.Duration(r.MaxDrawdownDuration).String()},
+		{"sharpe", fmt.Sprintf("%.2f", r.Sharpe)},
+		{"sortino", fmt.Sprintf("%.2f", r.Sortino)},
+		{"round trips", fmt.Sprint(r.RoundTrips)},
+		{"win rate", pct(r.WinRate)},
+		{"profit factor", fmt.Sprintf("%.2f", r.ProfitFactor)},
+		{"exposure", pct(r.Exposure)},
+		{"average trade", num(r.AverageTrade)},
+		{"fees", num(r.Fees)},
+	}
+}
+
+// WriteTable prints the report as an aligned two column table.
+func (r Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, row := range r.rows() {
+		fmt.Fprintf(tw, "%v\t%v\n", row[0], row[1])
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes the report as a JSON object with raw numbers. An
+// infinite profit factor, from round trips without losses, is written as
+// null.
+func (r Report) WriteJSON(w io.Writer) error {
+	type report Report
+	out := struct {
+		report
+		ProfitFactor *float64 `json:"profitFactor"`
+	}{report: report(r)}
+	if !math.IsInf(r.ProfitFactor, 0) {
+		out.ProfitFactor = &r.ProfitFactor
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(out)
+}
+
+// WriteCSV writes the report as metric,value rows with a header.
+func (r Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"metric", "value"})
+	for _, row := range r.rows() {
+		cw.Write(row[:])
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,151 @@
+// Package binance reads candles from the Binance klines endpoint.
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+	"github.com/thijs-nwl/algoProject/request"
+)
+
+// DefaultBaseURL is the public API endpoint of Binance.
+const DefaultBaseURL = "https://api.binance.com"
+
+// maxKlines is the most klines Binance returns for one request.
+const maxKlines = 1000
+
+var intervals = map[market.Period]string{
+	market.Period5m:  "5m",
+	market.Period15m: "15m",
+	market.Period30m: "30m",
+	market.Period2h:  "2h",
+	market.Period4h:  "4h",
+	market.Period1d:  "1d",
+}
+
+// Client fetches klines from the Binance public API.
+type Client struct {
+	BaseURL  string
+	Requests *request.Client
+}
+
+// NewClient returns a Client for the live Binance API.
+func NewClient() *Client {
+	r := request.New()
+	r.RequestsPerSecond = 10
+	return &Client{BaseURL: DefaultBaseURL, Requests: r}
+}
+
+// APIError is the {"code": ..., "msg": "..."} object Binance sends for
+// rejected requests.
+type APIError struct {
+	Code    int    `json:"code"`
+	Message string `json:"msg"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("binance: %v (code %d)", e.Message, e.Code)
+}
+
+// FetchCandles implements market.CandleSource. Ranges longer than one
+// request are fetched page by page.
+func (c *Client) FetchCandles(ctx context.Context, pair market.Pair, period market.Period, from, to time.Time) ([]market.Candle, error) {
+	interval, ok := intervals[period]
+	if !ok {
+		return nil, fmt.Errorf("binance: unsupported period %v", period)
+	}
+
+	var candles []market.Candle
+	for start := from; !start.After(to); {
+		page, err := c.klines(ctx, pair, interval, start, to)
+		if err != nil {
+			return nil, err
+		}
+		if len(page) == 0 {
+			break
+		}
+		candles = append(candles, page...)
+		start = page[len(page)-1].Time().Add(period.Duration())
+	}
+
+	if len(candles) == 0 {
+		return nil, market.ErrNoData
+	}
+	return market.Merge(candles), nil
+}
+
+func (c *Client) klines(ctx context.Context, pair market.Pair, interval string, from, to time.Time) ([]market.Candle, error) {
+	q := url.Values{}
+	q.Set("symbol", pair.Symbol(market.Binance))
+	q.Set("interval", interval)
+	q.Set("startTime", strconv.FormatInt(from.Unix()*1000, 10))
+	q.Set("endTime", strconv.FormatInt(to.Unix()*1000, 10))
+	q.Set("limit", strconv.Itoa(maxKlines))
+
+	body, err := c.Requests.Get(ctx, c.BaseURL+"/api/v3/klines?"+q.Encode())
+	if herr, ok := err.(*request.HTTPError); ok {
+		var apiErr APIError
+		if json.Unmarshal([]byte(herr.Body), &apiErr) == nil && apiErr.Message != "" {
+			return nil, &apiErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rows [][]interface{}
+	if err := json.Unmarshal(body, &rows); err != nil {
+		return nil, fmt.Errorf("binance: decoding klines: %v", err)
+	}
+
+	candles := make([]market.Candle, 0, len(rows))
+	for _, row := range rows {
+		c, err := parseKline(row)
+		if err != nil {
+			return nil, err
+		}
+		candles = append(candles, c)
+	}
+	return candles, nil
+}
+
+// parseKline converts a kline row. Binance counts volume in the base asset
+// and quote volume in the quote asset, the opposite of market.Candle.
+func parseKline(row []interface{}) (market.Candle, error) {
+	if len(row) < 8 {
+		return market.Candle{}, fmt.Errorf("binance: kline has %d fields, want at least 8", len(row))
+	}
+	openTime, ok := row[0].(float64)
+	if !ok {
+		return market.Candle{}, fmt.Errorf("binance: invalid kline open time %v", row[0])
+	}
+
+	var f [8]float64
+	for _, i := range []int{1, 2, 3, 4, 5, 7} {
+		s, _ := row[i].(string)
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return market.Candle{}, fmt.Errorf("binance: invalid kline field %d: %v", i, row[i])
+		}
+		f[i] = v
+	}
+
+	c := market.Candle{
+		Date:        int64(openTime) / 1000,
+		Open:        f[1],
+		High:        f[2],
+		Low:         f[3],
+		Close:       f[4],
+		QuoteVolume: f[5],
+		Volume:      f[7],
+	}
+	if c.QuoteVolume > 0 {
+		c.WeightedAverage = c.Volume / c.QuoteVolume
+	}
+	return c, nil
+}
@@ -0,0 +1,185 @@
+// Package candlebin stores candle series in a compact binary format.
+//
+// A file starts with a 16 byte header:
+//
+//	magic   [4]byte  "ALGC"
+//	version uint16   format version, 1 or 2
+//	flags   uint16   bit 0 set when the body is gzip compressed,
+//	                 bit 1 set when it is columnar (version 2 only)
+//	period  uint32   candle period in seconds
+//	count   uint32   number of candles
+//
+// Each candle is eight little endian values ordered by date: the date as an
+// int64 and open, high, low, close, volume, quote volume and weighted
+// average as float64s. In the row layout the body holds count fixed width
+// records of those 64 bytes. In the columnar layout, added in version 2,
+// it holds all dates, then all opens, and so on, which suits tools that
+// read one column at a time. Row files are still written as version 1.
+//
+// Either layout is fixed width, so uncompressed files are read in place
+// and finding the candle at a time takes a binary search; compressed files
+// are inflated into memory first.
+package candlebin
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// Version is the newest format version this package reads and writes.
+const Version = 2
+
+const (
+	headerSize = 16
+	recordSize = 64
+	fields     = 8
+
+	flagGzip     = 1 << 0
+	flagColumnar = 1 << 1
+)
+
+var magic = [4]byte{'A', 'L', 'G', 'C'}
+
+// ErrFormat is returned for data that is not a candlebin file.
+var ErrFormat = errors.New("candlebin: not a candle file")
+
+// Header describes a candle file.
+type Header struct {
+	Version    uint16
+	Compressed bool
+	Columnar   bool
+	Period     market.Period
+	Count      int
+}
+
+// Options select how Write lays out a file.
+type Options struct {
+	// Compress gzips the body.
+	Compress bool
+	// Columnar stores the candles column by column.
+	Columnar bool
+}
+
+// Write writes candles of period to w. Candles are sorted and
+// de-duplicated first.
+func Write(w io.Writer, period market.Period, candles []market.Candle, opts Options) error {
+	candles = market.Merge(candles)
+	if len(candles) > math.MaxUint32 {
+		return fmt.Errorf("candlebin: %d candles do not fit in one file", len(candles))
+	}
+
+	var flags uint16
+	version := uint16(1)
+	if opts.Compress {
+		flags |= flagGzip
+	}
+	if opts.Columnar {
+		flags |= flagColumnar
+		version = 2
+	}
+	hdr := make([]byte, headerSize)
+	copy(hdr, magic[:])
+	binary.LittleEndian.PutUint16(hdr[4:], version)
+	binary.LittleEndian.PutUint16(hdr[6:], flags)
+	binary.LittleEndian.PutUint32(hdr[8:], uint32(period))
+	binary.LittleEndian.PutUint32(hdr[12:], uint32(len(candles)))
+	if _, err := w.Write(hdr); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	var body io.Writer = bw
+	var zw *gzip.Writer
+	if opts.Compress {
+		zw = gzip.NewWriter(bw)
+		body = zw
+	}
+
+	n := len(candles)
+	buf := make([]byte, n*recordSize)
+	rec := make([]byte, recordSize)
+	for i, c := range candles {
+		if !opts.Columnar {
+			encode(buf[i*recordSize:], c)
+			continue
+		}
+		encode(rec, c)
+		for k := 0; k < fields; k++ {
+			copy(buf[(k*n+i)*8:], rec[k*8:k*8+8])
+		}
+	}
+	if _, err := body.Write(buf); err != nil {
+		return err
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Read reads a whole candle file from r.
+func Read(r io.Reader) (Header, []market.Candle, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return Header{}, nil, err
+	}
+	f, err := NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return Header{}, nil, err
+	}
+	candles, err := f.Slice(0, f.Len())
+	return f.Header(), candles, err
+}
+
+func encode(rec []byte, c market.Candle) {
+	binary.LittleEndian.PutUint64(rec[0:], uint64(c.Date))
+	for i, v := range []float64{c.Open, c.High, c.Low, c.Close, c.Volume, c.QuoteVolume, c.WeightedAverage} {
+		binary.LittleEndian.PutUint64(rec[8+8*i:], math.Float64bits(v))
+	}
+}
+
+func decode(rec []byte) market.Candle {
+	f := func(i int) float64 { return math.Float64frombits(binary.LittleEndian.Uint64(rec[8+8*i:])) }
+	return market.Candle{
+		Date:            int64(binary.LittleEndian.Uint64(rec[0:])),
+		Open:            f(0),
+		High:            f(1),
+		Low:             f(2),
+		Close:           f(3),
+		Volume:          f(4),
+		QuoteVolume:     f(5),
+		WeightedAverage: f(6),
+	}
+}
+
+func parseHeader(b []byte) (Header, error) {
+	if len(b) < headerSize || !bytes.Equal(b[:4], magic[:]) {
+		return Header{}, ErrFormat
+	}
+	h := Header{
+		Version: binary.LittleEndian.Uint16(b[4:]),
+		Period:  market.Period(binary.LittleEndian.Uint32(b[8:])),
+		Count:   int(binary.LittleEndian.Uint32(b[12:])),
+	}
+	if h.Version == 0 || h.Version > Version {
+		return Header{}, fmt.Errorf("candlebin: unsupported version %d", h.Version)
+	}
+	flags := binary.LittleEndian.Uint16(b[6:])
+	h.Compressed = flags&flagGzip != 0
+	h.Columnar = flags&flagColumnar != 0
+	if h.Columnar && h.Version < 2 {
+		return Header{}, fmt.Errorf("candlebin: columnar layout in a version %d file", h.Version)
+	}
+	return h, nil
+}
@@ -0,0 +1,51 @@
+package candlebin
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// FromJSON converts a JSON candle file, as stored by the datastore, into a
+// candle file of period at dst.
+func FromJSON(src, dst string, period market.Period, opts Options) error {
+	b, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	var candles []market.Candle
+	if err := json.Unmarshal(b, &candles); err != nil {
+		return err
+	}
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if err := Write(f, period, candles, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ToJSON converts the candle file at src into a JSON candle file at dst.
+func ToJSON(src, dst string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, candles, err := Read(f)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(candles)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dst, b, 0644)
+}
@@ -0,0 +1,166 @@
+package candlebin
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// File gives random access to the candles of a candle file.
+type File struct {
+	hdr     Header
+	records io.ReaderAt
+	closer  io.Closer
+}
+
+// Open opens the candle file at path. Close it when done.
+func Open(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	file, err := NewReader(f, fi.Size())
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+	file.closer = f
+	return file, nil
+}
+
+// NewReader reads a candle file of size bytes from r.
+func NewReader(r io.ReaderAt, size int64) (*File, error) {
+	b := make([]byte, headerSize)
+	if _, err := r.ReadAt(b, 0); err != nil {
+		if err == io.EOF {
+			return nil, ErrFormat
+		}
+		return nil, err
+	}
+	hdr, err := parseHeader(b)
+	if err != nil {
+		return nil, err
+	}
+
+	body := io.NewSectionReader(r, headerSize, size-headerSize)
+	var records io.ReaderAt = body
+	if hdr.Compressed {
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("candlebin: %v", err)
+		}
+		raw, err := ioutil.ReadAll(zr)
+		if err != nil {
+			return nil, fmt.Errorf("candlebin: %v", err)
+		}
+		records = bytes.NewReader(raw)
+		size = headerSize + int64(len(raw))
+	}
+
+	if want := headerSize + int64(hdr.Count)*recordSize; size < want {
+		return nil, fmt.Errorf("candlebin: truncated, %d candles need %d bytes, have %d", hdr.Count, want, size)
+	}
+	return &File{hdr: hdr, records: records}, nil
+}
+
+// Header returns the header of the file.
+func (f *File) Header() Header { return f.hdr }
+
+// Len returns the number of candles in the file.
+func (f *File) Len() int { return f.hdr.Count }
+
+// At returns the i-th candle.
+func (f *File) At(i int) (market.Candle, error) {
+	if i < 0 || i >= f.hdr.Count {
+		return market.Candle{}, fmt.Errorf("candlebin: index %d out of range [0, %d)", i, f.hdr.Count)
+	}
+	candles, err := f.Slice(i, i+1)
+	if err != nil {
+		return market.Candle{}, err
+	}
+	return candles[0], nil
+}
+
+// Slice returns the candles with index i up to but not including j.
+func (f *File) Slice(i, j int) ([]market.Candle, error) {
+	if i < 0 || j > f.hdr.Count || i > j {
+		return nil, fmt.Errorf("candlebin: slice [%d:%d] out of range [0, %d]", i, j, f.hdr.Count)
+	}
+	if i == j {
+		return []market.Candle{}, nil
+	}
+
+	n := j - i
+	buf := make([]byte, n*recordSize)
+	if !f.hdr.Columnar {
+		if _, err := f.records.ReadAt(buf, int64(i)*recordSize); err != nil {
+			return nil, err
+		}
+	} else {
+		// Read the slice of every column and interleave them into rows.
+		col := make([]byte, n*8)
+		for k := 0; k < fields; k++ {
+			if _, err := f.records.ReadAt(col, int64(k*f.hdr.Count+i)*8); err != nil {
+				return nil, err
+			}
+			for r := 0; r < n; r++ {
+				copy(buf[r*recordSize+k*8:], col[r*8:r*8+8])
+			}
+		}
+	}
+
+	candles := make([]market.Candle, n)
+	for k := range candles {
+		candles[k] = decode(buf[k*recordSize:])
+	}
+	return candles, nil
+}
+
+// Search returns the index of the first candle at or after t, or Len if
+// there is none.
+func (f *File) Search(t time.Time) (int, error) {
+	var err error
+	i := sort.Search(f.hdr.Count, func(i int) bool {
+		if err != nil {
+			return true
+		}
+		var c market.Candle
+		c, err = f.At(i)
+		return c.Date >= t.Unix()
+	})
+	return i, err
+}
+
+// Range returns the candles between from and to inclusive.
+func (f *File) Range(from, to time.Time) ([]market.Candle, error) {
+	i, err := f.Search(from)
+	if err != nil {
+		return nil, err
+	}
+	j, err := f.Search(to.Add(time.Second))
+	if err != nil {
+		return nil, err
+	}
+	return f.Slice(i, j)
+}
+
+// Close closes the underlying file, if Open opened one.
+func (f *File) Close() error {
+	if f.closer == nil {
+		return nil
+	}
+	return f.closer.Close()
+}
@@ -0,0 +1,149 @@
+// Package csvfile reads and writes candles as CSV, so OHLCV data from any
+// origin can be used where an exchange is expected and stored candles can
+// be opened in spreadsheets.
+package csvfile
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// Columns are the names Read recognizes in the header row. Only date,
+// open, high, low and close are required; the header is matched without
+// regard to case.
+var Columns = []string{"date", "open", "high", "low", "close", "volume", "quoteVolume", "weightedAverage"}
+
+// Source is a directory of CSV files named QUOTE_BASE_period.csv, such as
+// BTC_XMR_300.csv.
+type Source struct {
+	Dir string
+}
+
+// FetchCandles implements market.CandleSource.
+func (s *Source) FetchCandles(ctx context.Context, pair market.Pair, period market.Period, from, to time.Time) ([]market.Candle, error) {
+	f, err := os.Open(filepath.Join(s.Dir, fmt.Sprintf("%v_%v.csv", pair, period)))
+	if os.IsNotExist(err) {
+		return nil, market.ErrNoData
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	all, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", f.Name(), err)
+	}
+
+	var candles []market.Candle
+	for _, c := range all {
+		if c.Date >= from.Unix() && c.Date <= to.Unix() {
+			candles = append(candles, c)
+		}
+	}
+	if len(candles) == 0 {
+		return nil, market.ErrNoData
+	}
+	return candles, nil
+}
+
+// Read parses CSV candles with a header row. Dates may be Unix seconds,
+// Unix milliseconds, RFC 3339, "2006-01-02 15:04:05" or "2006-01-02" in
+// UTC. When the weighted average is missing it is derived from the
+// volumes. The result is ordered by date.
+func Read(r io.Reader) ([]market.Candle, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csvfile: reading header: %v", err)
+	}
+	col := make(map[string]int)
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, name := range Columns[:5] {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("csvfile: missing %q column", name)
+		}
+	}
+
+	var candles []market.Candle
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		c, err := parseRecord(rec, col)
+		if err != nil {
+			return nil, fmt.Errorf("csvfile: line %d: %v", line, err)
+		}
+		candles = append(candles, c)
+	}
+	return market.Merge(candles), nil
+}
+
+func parseRecord(rec []string, col map[string]int) (market.Candle, error) {
+	var c market.Candle
+	date, err := parseDate(rec[col["date"]])
+	if err != nil {
+		return c, err
+	}
+	c.Date = date
+
+	fields := map[string]*float64{
+		"open":            &c.Open,
+		"high":            &c.High,
+		"low":             &c.Low,
+		"close":           &c.Close,
+		"volume":          &c.Volume,
+		"quotevolume":     &c.QuoteVolume,
+		"weightedaverage": &c.WeightedAverage,
+	}
+	for name, dst := range fields {
+		i, ok := col[name]
+		if !ok || i >= len(rec) || rec[i] == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(rec[i]), 64)
+		if err != nil {
+			return c, fmt.Errorf("invalid %v %q", name, rec[i])
+		}
+		*dst = v
+	}
+
+	if c.WeightedAverage == 0 && c.QuoteVolume > 0 {
+		c.WeightedAverage = c.Volume / c.QuoteVolume
+	}
+	return c, nil
+}
+
+func parseDate(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if n > 1e11 {
+			n /= 1000
+		}
+		return n, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Unix(), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid date %q", s)
+}
@@ -0,0 +1,109 @@
+package csvfile
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// WriteOptions select what Write puts in a CSV file.
+type WriteOptions struct {
+	// Columns lists the columns to write, in order, from the names in
+	// Columns. All of them are written when empty.
+	Columns []string
+	// TimeFormat is how the date column is written: "unix" for seconds,
+	// "unixms" for milliseconds, or a time.Format layout applied in UTC.
+	// Seconds are used when empty.
+	TimeFormat string
+}
+
+// Write writes candles as CSV with a header row. The output reads back
+// with Read when the date and price columns are included and the date is
+// written in a form Read parses: "unix", "unixms", or a layout that
+// yields one of the date forms Read accepts, such as time.RFC3339.
+func Write(w io.Writer, candles []market.Candle, opts WriteOptions) error {
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = Columns
+	}
+	getters := make([]func(market.Candle) string, len(columns))
+	for i, name := range columns {
+		get, err := getter(name, opts.TimeFormat)
+		if err != nil {
+			return err
+		}
+		getters[i] = get
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	rec := make([]string, len(columns))
+	for _, c := range candles {
+		for i, get := range getters {
+			rec[i] = get(c)
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func getter(name, timeFormat string) (func(market.Candle) string, error) {
+	num := func(f func(market.Candle) float64) func(market.Candle) string {
+		return func(c market.Candle) string { return strconv.FormatFloat(f(c), 'f', -1, 64) }
+	}
+
+	switch strings.ToLower(name) {
+	case "date":
+		return dateFormatter(timeFormat), nil
+	case "open":
+		return num(func(c market.Candle) float64 { return c.Open }), nil
+	case "high":
+		return num(func(c market.Candle) float64 { return c.High }), nil
+	case "low":
+		return num(func(c market.Candle) float64 { return c.Low }), nil
+	case "close":
+		return num(func(c market.Candle) float64 { return c.Close }), nil
+	case "volume":
+		return num(func(c market.Candle) float64 { return c.Volume }), nil
+	case "quotevolume":
+		return num(func(c market.Candle) float64 { return c.QuoteVolume }), nil
+	case "weightedaverage":
+		return num(func(c market.Candle) float64 { return c.WeightedAverage }), nil
+	}
+	return nil, fmt.Errorf("csvfile: unknown column %q, want one of %v", name, strings.Join(Columns, ", "))
+}
+
+func dateFormatter(format string) func(market.Candle) string {
+	switch format {
+	case "", "unix":
+		return func(c market.Candle) string { return strconv.FormatInt(c.Date, 10) }
+	case "unixms":
+		return func(c market.Candle) string { return strconv.FormatInt(c.Date*1000, 10) }
+	}
+	return func(c market.Candle) string { return c.Time().Format(format) }
+}
+
+// ParseColumns splits a comma separated column list, checking every name.
+func ParseColumns(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var columns []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if _, err := getter(name, ""); err != nil {
+			return nil, err
+		}
+		columns = append(columns, name)
+	}
+	return columns, nil
+}
@@ -0,0 +1,120 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// Backend stores candle series. Store keeps them as JSON files and DB in a
+// single embedded database; the tools accept either through Open.
+type Backend interface {
+	// Load returns the stored candles of pair at period between from and
+	// to, ordered by date.
+	Load(pair market.Pair, period market.Period, from, to time.Time) ([]market.Candle, error)
+	// Save stores candles of pair at period. A candle for a date that is
+	// already stored replaces it.
+	Save(pair market.Pair, period market.Period, candles []market.Candle) error
+	// Stream is like Load but reads the candles one at a time.
+	Stream(pair market.Pair, period market.Period, from, to time.Time) (*Stream, error)
+	// Series lists the stored series.
+	Series() ([]Series, error)
+	// Holes returns the ranges recorded with SaveHoles, ordered by start.
+	Holes(pair market.Pair, period market.Period) ([]Range, error)
+	// SaveHoles records ranges of pair at period the exchange has no
+	// candles for, so Update does not ask for them again.
+	SaveHoles(pair market.Pair, period market.Period, holes []Range) error
+	Close() error
+}
+
+// Config selects the backend Open returns.
+type Config struct {
+	// Backend is "file" for a directory of candle files or "db" for an
+	// embedded database. The empty string means "file".
+	Backend string
+	// Dir is the datastore directory. The database is the file
+	// candles.db inside it.
+	Dir string
+	// Exchange keys the series in the database. Directories hold one
+	// exchange each, so the file backend ignores it.
+	Exchange market.Exchange
+}
+
+// DBName is the file name of the database inside the datastore directory.
+const DBName = "candles.db"
+
+// Backends lists the backend names Open accepts.
+var Backends = []string{"file", "db"}
+
+// Open returns the backend described by cfg.
+func Open(cfg Config) (Backend, error) {
+	switch cfg.Backend {
+	case "", "file":
+		return New(cfg.Dir), nil
+	case "db":
+		return OpenDB(filepath.Join(cfg.Dir, DBName), cfg.Exchange)
+	}
+	return nil, fmt.Errorf("datastore: unknown backend %q", cfg.Backend)
+}
+
+// Update makes sure every candle of pair between start and end is stored
+// in b. Only the ranges Plan returns are fetched, and they are saved
+// together. Parts of those ranges that come back empty and lie before the
+// newest candle known are recorded as holes: the exchange does not fill in
+// its past, so they are not asked for again. It returns the number of
+// candles that were added.
+func Update(ctx context.Context, b Backend, src market.CandleSource, pair market.Pair, period market.Period, start, end time.Time) (int, error) {
+	stored, err := b.Load(pair, period, start, end)
+	if err != nil {
+		return 0, err
+	}
+	holes, err := b.Holes(pair, period)
+	if err != nil {
+		return 0, err
+	}
+
+	var fetched [][]market.Candle
+	var empty []Range
+	for _, g := range plan(stored, holes, period, start, end) {
+		candles, err := src.FetchCandles(ctx, pair, period, g.Start, g.End)
+		if err != nil && err != market.ErrNoData {
+			return 0, err
+		}
+		fetched = append(fetched, candles)
+		empty = append(empty, Missing(append(candles, stored...), period, g.Start, g.End)...)
+	}
+
+	have := make(map[int64]bool, len(stored))
+	var newest int64
+	for _, c := range stored {
+		have[c.Date] = true
+		newest = c.Date
+	}
+	merged := market.Merge(fetched...)
+	added := 0
+	for _, c := range merged {
+		if !have[c.Date] {
+			added++
+		}
+		if c.Date > newest {
+			newest = c.Date
+		}
+	}
+	if err := b.Save(pair, period, merged); err != nil {
+		return 0, err
+	}
+
+	var permanent []Range
+	for _, h := range empty {
+		if h.End.Unix() < newest {
+			permanent = append(permanent, h)
+		}
+	}
+	if err := b.SaveHoles(pair, period, permanent); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
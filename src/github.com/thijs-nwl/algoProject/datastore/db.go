@@ -0,0 +1,758 @@
+package datastore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// DB is an embedded candle database in a single file. Candles are keyed by
+// exchange, pair, period and date, and saving a candle for a key that is
+// already stored replaces it, so re-downloaded ranges simply overwrite the
+// old values.
+//
+// The file is a log: an 8 byte magic followed by one record per Save.
+//
+//	length  uint32   size of the body
+//	crc     uint32   CRC-32 (IEEE) of the body
+//	body             a header holding the record kind, exchange, pair,
+//	                 period, candle count and the dates of the first and
+//	                 last candle, then the candles as eight little endian
+//	                 values each
+//
+// A holes record has the same header, counting ranges instead of candles,
+// followed by the start and end date of each range. The latest holes
+// record of a series replaces the earlier ones.
+//
+// Opening the database reads the file once to check every record against
+// its checksum, but keeps only the offset and date range of each record in
+// memory. Load and Stream read the records of one series that overlap the
+// range asked for, so memory use does not grow with the size of the file.
+// A last record that runs past the end of the file was cut short by a
+// crash and is cut off at open; a complete record that fails its checksum
+// means the file is damaged, and OpenDB returns an error rather than
+// dropping data.
+//
+// Every Save appends a record. A candle record without gaps makes the
+// earlier records of its series that lie within its range dead, as it
+// replaces all of their candles. When a series is spread over more than
+// maxRecords records, its candles are written again as a single snapshot
+// record that replaces the others, and when dead records take up more
+// than half of the file, the file is rewritten without them. Compact does
+// the same for every series at once.
+//
+// The format is our own rather than bbolt or SQLite because algoProject
+// builds with the standard library alone, and SQLite would also need cgo
+// or a large translated port. Candles are only ever appended and read
+// back as date ranges of one series, which a log of sorted records serves
+// without the page management of a general purpose store.
+//
+// A DB is safe for concurrent use by one process; the file must not be
+// opened by two processes at once.
+type DB struct {
+	exchange market.Exchange
+
+	mu     sync.Mutex
+	path   string
+	f      *os.File
+	size   int64
+	series map[seriesKey][]dbRecord
+	holes  map[seriesKey]dbRecord
+	live   int64
+	dead   int64
+}
+
+type seriesKey struct {
+	exchange market.Exchange
+	pair     market.Pair
+	period   market.Period
+}
+
+// The kinds of record. A snapshot replaces every earlier candle record of
+// its series.
+const (
+	kindCandles  = 1
+	kindSnapshot = 2
+	kindHoles    = 3
+)
+
+const (
+	candleSize = 64
+	holeSize   = 16
+	frameSize  = 8
+
+	// maxRecords is how many records a series may span before Save
+	// writes it out as a snapshot.
+	maxRecords = 16
+	// minRewrite is the least number of bytes of replaced records worth
+	// rewriting the file for.
+	minRewrite = 1 << 20
+)
+
+var dbMagic = [8]byte{'A', 'L', 'G', 'D', 'B', 0, 0, 2}
+
+// ErrDBFormat is returned when a file is not a candle database.
+var ErrDBFormat = errors.New("datastore: not a candle database")
+
+var (
+	errChecksum = errors.New("datastore: record checksum mismatch")
+	errLength   = errors.New("datastore: record length does not match its header")
+	errClosed   = errors.New("datastore: database is closed")
+)
+
+// recordHeader starts the body of every record.
+type recordHeader struct {
+	kind        byte
+	key         seriesKey
+	count       int
+	first, last int64
+}
+
+// dbRecord is a record in the file: its header, and where the record
+// starts and how long it is including the length and checksum.
+type dbRecord struct {
+	recordHeader
+	off, size int64
+}
+
+// OpenDB opens the database at path, creating it if it does not exist.
+// Load and Save work on the series of exchange; series of other exchanges
+// in the same file are kept as they are.
+func OpenDB(path string, exchange market.Exchange) (*DB, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	db := &DB{exchange: exchange, path: path, f: f}
+	if err := db.scan(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("datastore: %v: %v", path, err)
+	}
+	return db, nil
+}
+
+// Load implements Backend.
+func (db *DB) Load(pair market.Pair, period market.Period, from, to time.Time) ([]market.Candle, error) {
+	st, err := db.Stream(pair, period, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return collect(st)
+}
+
+// Stream opens the stored candles of pair at period between from and to
+// for reading one at a time. The stream reads the file through a handle
+// of its own, so it stays valid when a later Save rewrites the file.
+// Close the stream when done.
+func (db *DB) Stream(pair market.Pair, period market.Period, from, to time.Time) (*Stream, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.f == nil {
+		return nil, errClosed
+	}
+	return db.stream(seriesKey{db.exchange, pair, period}, from, to)
+}
+
+func (db *DB) stream(key seriesKey, from, to time.Time) (*Stream, error) {
+	f, err := os.Open(db.path)
+	if err != nil {
+		return nil, err
+	}
+	st := newStream(from, to)
+	st.closers = append(st.closers, f)
+	for _, rec := range db.series[key] {
+		if rec.last < from.Unix() || rec.first > to.Unix() {
+			continue
+		}
+		rr, err := openRecord(f, rec)
+		if err == nil {
+			err = st.add(fmt.Sprintf("%v at %d", db.path, rec.off), rr.Next)
+		}
+		if err != nil {
+			st.Close()
+			return nil, err
+		}
+	}
+	return st, nil
+}
+
+// Save implements Backend. The candles are appended to the file as one
+// record and synced to disk before Save returns.
+func (db *DB) Save(pair market.Pair, period market.Period, candles []market.Candle) error {
+	candles = market.Merge(candles)
+	if len(candles) == 0 {
+		return nil
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.f == nil {
+		return errClosed
+	}
+	key := seriesKey{db.exchange, pair, period}
+	if err := db.append(kindCandles, key, candles); err != nil {
+		return err
+	}
+	if len(db.series[key]) > maxRecords {
+		if err := db.snapshot(key); err != nil {
+			return err
+		}
+	}
+	if db.dead > db.live && db.dead >= minRewrite {
+		return db.rewrite()
+	}
+	return nil
+}
+
+// Holes implements Backend.
+func (db *DB) Holes(pair market.Pair, period market.Period) ([]Range, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.f == nil {
+		return nil, errClosed
+	}
+	return db.readHoles(seriesKey{db.exchange, pair, period})
+}
+
+// SaveHoles implements Backend. The holes are merged with the ones already
+// recorded and written as a record that replaces the previous one.
+func (db *DB) SaveHoles(pair market.Pair, period market.Period, holes []Range) error {
+	if len(holes) == 0 {
+		return nil
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.f == nil {
+		return errClosed
+	}
+	key := seriesKey{db.exchange, pair, period}
+	old, err := db.readHoles(key)
+	if err != nil {
+		return err
+	}
+	holes = mergeRanges(append(old, holes...), period)
+
+	h := recordHeader{
+		kind:  kindHoles,
+		key:   key,
+		count: len(holes),
+		first: holes[0].Start.Unix(),
+		last:  holes[len(holes)-1].End.Unix(),
+	}
+	payload := make([]byte, len(holes)*holeSize)
+	for i, r := range holes {
+		binary.LittleEndian.PutUint64(payload[i*holeSize:], uint64(r.Start.Unix()))
+		binary.LittleEndian.PutUint64(payload[i*holeSize+8:], uint64(r.End.Unix()))
+	}
+	return db.write(h, payload)
+}
+
+// readHoles returns the ranges of the holes record of key, if any.
+func (db *DB) readHoles(key seriesKey) ([]Range, error) {
+	rec, ok := db.holes[key]
+	if !ok {
+		return nil, nil
+	}
+	frame := make([]byte, rec.size)
+	if _, err := db.f.ReadAt(frame, rec.off); err != nil {
+		return nil, err
+	}
+	body := frame[frameSize:]
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(frame[4:]) {
+		return nil, errChecksum
+	}
+	_, hn, err := readRecordHeader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	holes := make([]Range, rec.count)
+	for i := range holes {
+		b := body[hn+i*holeSize:]
+		holes[i] = Range{
+			Start: time.Unix(int64(binary.LittleEndian.Uint64(b)), 0).UTC(),
+			End:   time.Unix(int64(binary.LittleEndian.Uint64(b[8:])), 0).UTC(),
+		}
+	}
+	return holes, nil
+}
+
+// Compact writes every series spread over more than one record as a
+// single snapshot, one series at a time, and then rewrites the file
+// without the records they replace.
+func (db *DB) Compact() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.f == nil {
+		return errClosed
+	}
+	for _, key := range db.keys() {
+		if len(db.series[key]) > 1 {
+			if err := db.snapshot(key); err != nil {
+				return err
+			}
+		}
+	}
+	if db.dead == 0 {
+		return nil
+	}
+	return db.rewrite()
+}
+
+// Close closes the database file.
+func (db *DB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.f == nil {
+		return nil
+	}
+	err := db.f.Close()
+	db.f = nil
+	return err
+}
+
+// keys returns the series keys ordered by exchange, pair and period.
+func (db *DB) keys() []seriesKey {
+	keys := make([]seriesKey, 0, len(db.series))
+	for k := range db.series {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.exchange != b.exchange {
+			return a.exchange < b.exchange
+		}
+		if a.pair != b.pair {
+			return a.pair.String() < b.pair.String()
+		}
+		return a.period < b.period
+	})
+	return keys
+}
+
+// snapshot loads the series of key and writes it back as one record that
+// replaces all of its earlier ones.
+func (db *DB) snapshot(key seriesKey) error {
+	first, last := db.span(key)
+	st, err := db.stream(key, first, last)
+	if err != nil {
+		return err
+	}
+	candles, err := collect(st)
+	if err != nil {
+		return err
+	}
+	return db.append(kindSnapshot, key, candles)
+}
+
+// span returns the dates of the first and last candle of the series of
+// key.
+func (db *DB) span(key seriesKey) (time.Time, time.Time) {
+	recs := db.series[key]
+	first, last := recs[0].first, recs[0].last
+	for _, rec := range recs[1:] {
+		if rec.first < first {
+			first = rec.first
+		}
+		if rec.last > last {
+			last = rec.last
+		}
+	}
+	return time.Unix(first, 0).UTC(), time.Unix(last, 0).UTC()
+}
+
+// append writes candles, which must be ordered by date, as a record at the
+// end of the file.
+func (db *DB) append(kind byte, key seriesKey, candles []market.Candle) error {
+	h := recordHeader{
+		kind:  kind,
+		key:   key,
+		count: len(candles),
+		first: candles[0].Date,
+		last:  candles[len(candles)-1].Date,
+	}
+	payload := make([]byte, len(candles)*candleSize)
+	for i, c := range candles {
+		putCandle(payload[i*candleSize:], c)
+	}
+	return db.write(h, payload)
+}
+
+// write appends a record of header h and payload to the file and syncs
+// it.
+func (db *DB) write(h recordHeader, payload []byte) error {
+	var body bytes.Buffer
+	h.write(&body)
+	body.Write(payload)
+
+	frame := make([]byte, frameSize+body.Len())
+	binary.LittleEndian.PutUint32(frame[0:], uint32(body.Len()))
+	binary.LittleEndian.PutUint32(frame[4:], crc32.ChecksumIEEE(body.Bytes()))
+	copy(frame[frameSize:], body.Bytes())
+	if _, err := db.f.WriteAt(frame, db.size); err != nil {
+		db.f.Truncate(db.size)
+		return err
+	}
+	if err := db.f.Sync(); err != nil {
+		return err
+	}
+
+	db.add(dbRecord{recordHeader: h, off: db.size, size: int64(len(frame))})
+	db.size += int64(len(frame))
+	return nil
+}
+
+// add records rec in the in-memory index.
+func (db *DB) add(rec dbRecord) {
+	if rec.kind == kindHoles {
+		if old, ok := db.holes[rec.key]; ok {
+			db.live -= old.size
+			db.dead += old.size
+		}
+		db.holes[rec.key] = rec
+		db.live += rec.size
+		return
+	}
+	var kept []dbRecord
+	for _, old := range db.series[rec.key] {
+		if rec.kind == kindSnapshot || rec.covers(old) {
+			db.live -= old.size
+			db.dead += old.size
+			continue
+		}
+		kept = append(kept, old)
+	}
+	db.series[rec.key] = append(kept, rec)
+	db.live += rec.size
+}
+
+// covers reports whether rec holds a candle for every period from the
+// first to the last candle of old, so that reading old can never yield a
+// candle rec does not replace.
+func (rec dbRecord) covers(old dbRecord) bool {
+	step := int64(rec.key.period)
+	if step <= 0 || old.first < rec.first || old.last > rec.last {
+		return false
+	}
+	span := rec.last - rec.first
+	return span%step == 0 && int64(rec.count) == span/step+1
+}
+
+// scan reads the record headers of the file into memory and checks every
+// record against its checksum. A new file gets the magic written. A last
+// record that runs past the end of the file was cut short by a crash and
+// is removed, so the next Save appends after the last good one. A
+// complete record that is damaged is an error.
+func (db *DB) scan() error {
+	db.series = make(map[seriesKey][]dbRecord)
+	db.holes = make(map[seriesKey]dbRecord)
+	db.live, db.dead = 0, 0
+
+	info, err := db.f.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size()
+	if size == 0 {
+		db.size = int64(len(dbMagic))
+		_, err := db.f.WriteAt(dbMagic[:], 0)
+		return err
+	}
+
+	var m [8]byte
+	if _, err := db.f.ReadAt(m[:], 0); err != nil || m != dbMagic {
+		return ErrDBFormat
+	}
+
+	var recs []dbRecord
+	off := int64(len(m))
+	for off < size {
+		rec, err := readRecord(db.f, off, size)
+		if err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("record at %d: %v", off, err)
+		}
+		recs = append(recs, rec)
+		off += rec.size
+	}
+	if off < size {
+		if err := db.f.Truncate(off); err != nil {
+			return err
+		}
+	}
+
+	for _, rec := range recs {
+		db.add(rec)
+	}
+	db.size = off
+	return nil
+}
+
+// rewrite copies the records that are still in use to a new file, in
+// their original order, and renames it over the old one.
+func (db *DB) rewrite() error {
+	var recs []dbRecord
+	for _, rs := range db.series {
+		recs = append(recs, rs...)
+	}
+	for _, rec := range db.holes {
+		recs = append(recs, rec)
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].off < recs[j].off })
+
+	tmp, err := ioutil.TempFile(filepath.Dir(db.path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	err = tmp.Chmod(0644)
+	if err == nil {
+		_, err = w.Write(dbMagic[:])
+	}
+	for _, rec := range recs {
+		if err != nil {
+			break
+		}
+		_, err = io.Copy(w, io.NewSectionReader(db.f, rec.off, rec.size))
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), db.path)
+	}
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+
+	db.f.Close()
+	db.f = tmp
+	return db.scan()
+}
+
+// readRecord reads the header of the record at off and checks the record
+// against its checksum. It returns io.ErrUnexpectedEOF for a record that
+// was not written completely, one that does not fit in size bytes, and
+// errChecksum, errLength or ErrDBFormat for a complete record that is
+// damaged.
+func readRecord(f io.ReaderAt, off, size int64) (dbRecord, error) {
+	var frame [frameSize]byte
+	if off+frameSize > size {
+		return dbRecord{}, io.ErrUnexpectedEOF
+	}
+	if _, err := f.ReadAt(frame[:], off); err != nil {
+		return dbRecord{}, err
+	}
+	n := int64(binary.LittleEndian.Uint32(frame[0:]))
+	if off+frameSize+n > size {
+		return dbRecord{}, io.ErrUnexpectedEOF
+	}
+
+	rec := dbRecord{off: off, size: frameSize + n}
+	ok, err := checkRecord(f, rec)
+	if err != nil {
+		return dbRecord{}, err
+	}
+	if !ok {
+		return dbRecord{}, errChecksum
+	}
+	h, hn, err := readRecordHeader(bufio.NewReaderSize(io.NewSectionReader(f, off+frameSize, n), 128))
+	if err != nil {
+		return dbRecord{}, ErrDBFormat
+	}
+	if int64(hn)+int64(h.count)*entrySize(h.kind) != n {
+		return dbRecord{}, errLength
+	}
+	rec.recordHeader = h
+	return rec, nil
+}
+
+// checkRecord reports whether the body of rec matches its checksum.
+func checkRecord(f io.ReaderAt, rec dbRecord) (bool, error) {
+	var frame [frameSize]byte
+	if _, err := f.ReadAt(frame[:], rec.off); err != nil {
+		return false, err
+	}
+	crc := crc32.NewIEEE()
+	if _, err := io.Copy(crc, io.NewSectionReader(f, rec.off+frameSize, rec.size-frameSize)); err != nil {
+		return false, err
+	}
+	return crc.Sum32() == binary.LittleEndian.Uint32(frame[4:]), nil
+}
+
+// recordReader reads the candles of one record in order, checking the
+// record's checksum once the last candle has been read.
+type recordReader struct {
+	r    *bufio.Reader
+	crc  hash.Hash32
+	want uint32
+	left int
+	buf  [candleSize]byte
+}
+
+func openRecord(f io.ReaderAt, rec dbRecord) (*recordReader, error) {
+	var frame [frameSize]byte
+	if _, err := f.ReadAt(frame[:], rec.off); err != nil {
+		return nil, err
+	}
+	crc := crc32.NewIEEE()
+	body := io.TeeReader(io.NewSectionReader(f, rec.off+frameSize, rec.size-frameSize), crc)
+	rr := &recordReader{
+		r:    bufio.NewReader(body),
+		crc:  crc,
+		want: binary.LittleEndian.Uint32(frame[4:]),
+		left: rec.count,
+	}
+	if _, _, err := readRecordHeader(rr.r); err != nil {
+		return nil, err
+	}
+	return rr, nil
+}
+
+// Next returns the next candle of the record, or io.EOF after the last.
+func (rr *recordReader) Next() (market.Candle, error) {
+	if rr.left == 0 {
+		if _, err := io.Copy(ioutil.Discard, rr.r); err != nil {
+			return market.Candle{}, err
+		}
+		if rr.crc.Sum32() != rr.want {
+			return market.Candle{}, errChecksum
+		}
+		return market.Candle{}, io.EOF
+	}
+	if _, err := io.ReadFull(rr.r, rr.buf[:]); err != nil {
+		return market.Candle{}, err
+	}
+	rr.left--
+	return getCandle(rr.buf[:]), nil
+}
+
+func (h recordHeader) write(buf *bytes.Buffer) {
+	buf.WriteByte(h.kind)
+	writeString(buf, string(h.key.exchange))
+	writeString(buf, h.key.pair.String())
+	var b [24]byte
+	binary.LittleEndian.PutUint32(b[0:], uint32(h.key.period))
+	binary.LittleEndian.PutUint32(b[4:], uint32(h.count))
+	binary.LittleEndian.PutUint64(b[8:], uint64(h.first))
+	binary.LittleEndian.PutUint64(b[16:], uint64(h.last))
+	buf.Write(b[:])
+}
+
+// readRecordHeader reads a record header and returns its size in bytes.
+func readRecordHeader(r io.Reader) (recordHeader, int, error) {
+	var h recordHeader
+	var kind [1]byte
+	if _, err := io.ReadFull(r, kind[:]); err != nil {
+		return h, 0, err
+	}
+	if entrySize(kind[0]) == 0 {
+		return h, 0, ErrDBFormat
+	}
+	exchange, err := readString(r)
+	if err != nil {
+		return h, 0, err
+	}
+	name, err := readString(r)
+	if err != nil {
+		return h, 0, err
+	}
+	var b [24]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return h, 0, err
+	}
+	pair, err := market.ParsePair(name)
+	if err != nil {
+		return h, 0, err
+	}
+
+	h = recordHeader{
+		kind:  kind[0],
+		key:   seriesKey{market.Exchange(exchange), pair, market.Period(binary.LittleEndian.Uint32(b[0:]))},
+		count: int(binary.LittleEndian.Uint32(b[4:])),
+		first: int64(binary.LittleEndian.Uint64(b[8:])),
+		last:  int64(binary.LittleEndian.Uint64(b[16:])),
+	}
+	return h, 1 + 2 + len(exchange) + 2 + len(name) + len(b), nil
+}
+
+// entrySize returns the size of the entries that follow the header of a
+// record of kind, or 0 for an unknown kind.
+func entrySize(kind byte) int64 {
+	switch kind {
+	case kindCandles, kindSnapshot:
+		return candleSize
+	case kindHoles:
+		return holeSize
+	}
+	return 0
+}
+
+func putCandle(b []byte, c market.Candle) {
+	binary.LittleEndian.PutUint64(b, uint64(c.Date))
+	for i, v := range []float64{c.Open, c.High, c.Low, c.Close, c.Volume, c.QuoteVolume, c.WeightedAverage} {
+		binary.LittleEndian.PutUint64(b[8+8*i:], math.Float64bits(v))
+	}
+}
+
+func getCandle(b []byte) market.Candle {
+	v := func(i int) float64 { return math.Float64frombits(binary.LittleEndian.Uint64(b[8+8*i:])) }
+	return market.Candle{
+		Date:            int64(binary.LittleEndian.Uint64(b)),
+		Open:            v(0),
+		High:            v(1),
+		Low:             v(2),
+		Close:           v(3),
+		Volume:          v(4),
+		QuoteVolume:     v(5),
+		WeightedAverage: v(6),
+	}
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	var n [2]byte
+	binary.LittleEndian.PutUint16(n[:], uint16(len(s)))
+	buf.Write(n[:])
+	buf.WriteString(s)
+}
+
+func readString(r io.Reader) (string, error) {
+	var n [2]byte
+	if _, err := io.ReadFull(r, n[:]); err != nil {
+		return "", err
+	}
+	b := make([]byte, binary.LittleEndian.Uint16(n[:]))
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,215 @@
+package datastore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+func openTestDB(t *testing.T, path string) *DB {
+	db, err := OpenDB(path, "poloniex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func saveDB(t *testing.T, db *DB, cs []market.Candle) {
+	if err := db.Save(testPair, market.Period5m, cs); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func loadDB(t *testing.T, db *DB) []market.Candle {
+	got, err := db.Load(testPair, market.Period5m, at(0), at(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Size()
+}
+
+func TestDBReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DBName)
+	db := openTestDB(t, path)
+	saveDB(t, db, candles(1, span(0, 9)...))
+	saveDB(t, db, candles(2, span(5, 14)...))
+	if err := db.SaveHoles(testPair, market.Period5m, []Range{r(20, 22)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Save(testPair, market.Period1h, candles(3, 0)); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	db = openTestDB(t, path)
+	defer db.Close()
+	want := append(candles(1, span(0, 4)...), candles(2, span(5, 14)...)...)
+	if got := loadDB(t, db); !sameCandles(got, want) {
+		t.Errorf("after reopening loaded %v, want %v", got, want)
+	}
+	holes, err := db.Holes(testPair, market.Period5m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Range{r(20, 22)}; formatRanges(holes) != formatRanges(want) {
+		t.Errorf("after reopening holes %v, want %v", formatRanges(holes), formatRanges(want))
+	}
+	series, err := db.Series()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != 2 || series[0].Count != 15 || series[1].Count != 1 {
+		t.Errorf("after reopening series %+v, want 15 five minute and 1 hourly candle", series)
+	}
+}
+
+func TestDBTornTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DBName)
+	db := openTestDB(t, path)
+	saveDB(t, db, candles(1, span(0, 9)...))
+	good := fileSize(t, path)
+	saveDB(t, db, candles(2, span(20, 29)...))
+	db.Close()
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cut := range []int64{1, candleSize, int64(len(b)) - good - 1} {
+		if err := ioutil.WriteFile(path, b[:good+cut], 0644); err != nil {
+			t.Fatal(err)
+		}
+		db = openTestDB(t, path)
+		if got, want := loadDB(t, db), candles(1, span(0, 9)...); !sameCandles(got, want) {
+			t.Errorf("torn after %d bytes: loaded %v, want %v", cut, got, want)
+		}
+		if size := fileSize(t, path); size != good {
+			t.Errorf("torn after %d bytes: file is %d bytes, want it cut back to %d", cut, size, good)
+		}
+		db.Close()
+	}
+
+	db = openTestDB(t, path)
+	saveDB(t, db, candles(3, span(10, 12)...))
+	db.Close()
+	db = openTestDB(t, path)
+	defer db.Close()
+	if got, want := loadDB(t, db), append(candles(1, span(0, 9)...), candles(3, span(10, 12)...)...); !sameCandles(got, want) {
+		t.Errorf("saving after recovery: loaded %v, want %v", got, want)
+	}
+}
+
+func TestDBCorruptRecord(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, DBName)
+	db := openTestDB(t, path)
+	saveDB(t, db, candles(1, span(0, 9)...))
+	first := fileSize(t, path)
+	saveDB(t, db, candles(2, span(20, 29)...))
+	db.Close()
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, off := range []int64{first - 1, int64(len(b)) - 1, first + frameSize + 1} {
+		damaged := append([]byte(nil), b...)
+		damaged[off] ^= 0xff
+		p := filepath.Join(dir, "damaged.db")
+		if err := ioutil.WriteFile(p, damaged, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if db, err := OpenDB(p, "poloniex"); err == nil {
+			db.Close()
+			t.Errorf("byte %d flipped: OpenDB succeeded, want an error", off)
+		}
+		if size := fileSize(t, p); size != int64(len(b)) {
+			t.Errorf("byte %d flipped: file is %d bytes, want it left at %d", off, size, len(b))
+		}
+	}
+}
+
+func TestDBCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DBName)
+	db := openTestDB(t, path)
+	defer db.Close()
+
+	// Every other candle, so no record covers an earlier one.
+	var want []market.Candle
+	for i := int64(0); i < 5; i++ {
+		cs := candles(float64(i+1), 2*i, 2*i+10)
+		saveDB(t, db, cs)
+		want = market.Merge(want, cs)
+	}
+	before := fileSize(t, path)
+	if err := db.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(db.series[seriesKey{"poloniex", testPair, market.Period5m}]); n != 1 {
+		t.Errorf("after Compact the series has %d records, want 1", n)
+	}
+	if after := fileSize(t, path); after >= before {
+		t.Errorf("Compact left the file at %d bytes, was %d", after, before)
+	}
+	if got := loadDB(t, db); !sameCandles(got, want) {
+		t.Errorf("after Compact loaded %v, want %v", got, want)
+	}
+
+	db.Close()
+	db = openTestDB(t, path)
+	if got := loadDB(t, db); !sameCandles(got, want) {
+		t.Errorf("after Compact and reopening loaded %v, want %v", got, want)
+	}
+}
+
+func TestDBCoveredRecordsDead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DBName)
+	db := openTestDB(t, path)
+	defer db.Close()
+	key := seriesKey{"poloniex", testPair, market.Period5m}
+
+	saveDB(t, db, candles(1, span(0, 9)...))
+	saveDB(t, db, candles(2, 0, 5, 9))
+	if n := len(db.series[key]); n != 2 {
+		t.Fatalf("a record with gaps replaced %d records, want none", 3-n)
+	}
+	saveDB(t, db, candles(3, span(0, 19)...))
+	if n := len(db.series[key]); n != 1 {
+		t.Errorf("a record without gaps left %d records, want 1", n)
+	}
+	if got, want := loadDB(t, db), candles(3, span(0, 19)...); !sameCandles(got, want) {
+		t.Errorf("loaded %v, want %v", got, want)
+	}
+}
+
+func TestDBRepeatedSaveRewrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DBName)
+	db := openTestDB(t, path)
+	defer db.Close()
+
+	// Each save is about 320KB, and replaces the one before it.
+	cs := candles(1, span(0, 4999)...)
+	for i := 0; i < 30; i++ {
+		for j := range cs {
+			cs[j].Close = float64(i)
+		}
+		saveDB(t, db, cs)
+	}
+	if size, max := fileSize(t, path), int64(len(cs)*candleSize)+2*minRewrite; size > max {
+		t.Errorf("after repeated saves the file is %d bytes, want at most %d", size, max)
+	}
+	if got := loadDB(t, db); !sameCandles(got, cs) {
+		t.Errorf("loaded %d candles closing at %v, want the last save", len(got), got[0].Close)
+	}
+}
@@ -0,0 +1,145 @@
+package datastore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// SaveTrades stores trades of pair in a file named after the pair and the
+// dates of its first and last trade, such as
+// BTC_XMR_trades_1516406390_1516406398.json.
+func (s *Store) SaveTrades(pair market.Pair, trades []market.Trade) error {
+	trades = market.MergeTrades(trades)
+	if len(trades) == 0 {
+		return nil
+	}
+
+	b, err := json.Marshal(trades)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%v_trades_%v_%v.json", pair, trades[0].Date, trades[len(trades)-1].Date)
+	return s.writeAtomic(name, b)
+}
+
+// LoadTrades returns the stored trades of pair between from and to, read
+// from every trade file that overlaps the range.
+func (s *Store) LoadTrades(pair market.Pair, from, to time.Time) ([]market.Trade, error) {
+	files, err := s.glob(pair, "trades")
+	if err != nil {
+		return nil, err
+	}
+
+	var lists [][]market.Trade
+	for _, f := range files {
+		if len(f.times) != 2 || f.times[0] > to.Unix() || f.times[1] < from.Unix() {
+			continue
+		}
+		var trades []market.Trade
+		if err := s.readJSON(f.name, &trades); err != nil {
+			return nil, err
+		}
+		lists = append(lists, trades)
+	}
+
+	var trades []market.Trade
+	for _, t := range market.MergeTrades(lists...) {
+		if t.Date >= from.Unix() && t.Date <= to.Unix() {
+			trades = append(trades, t)
+		}
+	}
+	return trades, nil
+}
+
+// SaveOrderBook stores a snapshot of the order book of pair in a file
+// named after the pair and the snapshot date, such as
+// BTC_XMR_book_1516406400.json.
+func (s *Store) SaveOrderBook(pair market.Pair, book *market.OrderBook) error {
+	b, err := json.Marshal(book)
+	if err != nil {
+		return err
+	}
+	return s.writeAtomic(fmt.Sprintf("%v_book_%v.json", pair, book.Date), b)
+}
+
+// LoadOrderBooks returns the stored order book snapshots of pair taken
+// between from and to, oldest first.
+func (s *Store) LoadOrderBooks(pair market.Pair, from, to time.Time) ([]market.OrderBook, error) {
+	files, err := s.glob(pair, "book")
+	if err != nil {
+		return nil, err
+	}
+
+	var books []market.OrderBook
+	for _, f := range files {
+		if len(f.times) != 1 || f.times[0] < from.Unix() || f.times[0] > to.Unix() {
+			continue
+		}
+		var book market.OrderBook
+		if err := s.readJSON(f.name, &book); err != nil {
+			return nil, err
+		}
+		books = append(books, book)
+	}
+	return books, nil
+}
+
+// OrderBookAt returns the latest stored snapshot of pair taken at or
+// before t, for estimating fills in a backtest. It reports false when
+// there is none.
+func (s *Store) OrderBookAt(pair market.Pair, t time.Time) (*market.OrderBook, bool, error) {
+	books, err := s.LoadOrderBooks(pair, time.Unix(0, 0), t)
+	if err != nil || len(books) == 0 {
+		return nil, false, err
+	}
+	return &books[len(books)-1], true, nil
+}
+
+type depthFile struct {
+	name  string
+	times []int64
+}
+
+// glob lists the files of pair of the given kind together with the Unix
+// times in their names, in name order.
+func (s *Store) glob(pair market.Pair, kind string) ([]depthFile, error) {
+	prefix := fmt.Sprintf("%v_%v_", pair, kind)
+	paths, err := filepath.Glob(filepath.Join(s.Dir, prefix+"*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var files []depthFile
+	for _, p := range paths {
+		name := filepath.Base(p)
+		f := depthFile{name: name}
+		for _, part := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".json"), "_") {
+			t, err := strconv.ParseInt(part, 10, 64)
+			if err != nil {
+				f.times = nil
+				break
+			}
+			f.times = append(f.times, t)
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+func (s *Store) readJSON(name string, v interface{}) error {
+	b, err := ioutil.ReadFile(filepath.Join(s.Dir, name))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("datastore: reading %v: %v", name, err)
+	}
+	return nil
+}
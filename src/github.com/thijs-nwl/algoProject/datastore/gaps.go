@@ -0,0 +1,112 @@
+package datastore
+
+import (
+	"sort"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// Range is an inclusive span of candle times.
+type Range struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Missing returns the ranges between start and end for which candles holds
+// no candle. Candle times are taken to be multiples of period since the
+// Unix epoch, the way the exchange aligns them. Gaps at the head, at the
+// tail and in between are all reported.
+func Missing(candles []market.Candle, period market.Period, start, end time.Time) []Range {
+	have := make(map[int64]bool, len(candles))
+	for _, c := range candles {
+		have[c.Date] = true
+	}
+
+	step := int64(period)
+	first := (start.Unix() + step - 1) / step * step
+
+	var gaps []Range
+	var open *Range
+	for t := first; t <= end.Unix(); t += step {
+		if have[t] {
+			open = nil
+			continue
+		}
+		if open == nil {
+			gaps = append(gaps, Range{Start: time.Unix(t, 0)})
+			open = &gaps[len(gaps)-1]
+		}
+		open.End = time.Unix(t, 0)
+	}
+	return gaps
+}
+
+// joinGap is the most stored candles that may separate two gaps for Plan
+// to fetch them with one request, fetching the stored candles again.
+const joinGap = 500
+
+// Plan returns the ranges Update would fetch to complete pair between
+// start and end in b: the missing ranges that are not recorded holes,
+// with gaps that lie close together joined into one.
+func Plan(b Backend, pair market.Pair, period market.Period, start, end time.Time) ([]Range, error) {
+	stored, err := b.Load(pair, period, start, end)
+	if err != nil {
+		return nil, err
+	}
+	holes, err := b.Holes(pair, period)
+	if err != nil {
+		return nil, err
+	}
+	return plan(stored, holes, period, start, end), nil
+}
+
+func plan(stored []market.Candle, holes []Range, period market.Period, start, end time.Time) []Range {
+	gaps := subtract(Missing(stored, period, start, end), holes, period)
+	return join(gaps, period, joinGap)
+}
+
+// subtract returns the parts of gaps that no hole covers. All ranges are
+// aligned to period.
+func subtract(gaps, holes []Range, period market.Period) []Range {
+	for _, h := range holes {
+		var left []Range
+		for _, g := range gaps {
+			if h.End.Before(g.Start) || h.Start.After(g.End) {
+				left = append(left, g)
+				continue
+			}
+			if g.Start.Before(h.Start) {
+				left = append(left, Range{Start: g.Start, End: h.Start.Add(-period.Duration())})
+			}
+			if g.End.After(h.End) {
+				left = append(left, Range{Start: h.End.Add(period.Duration()), End: g.End})
+			}
+		}
+		gaps = left
+	}
+	return gaps
+}
+
+// join merges ranges, ordered by start, that are at most n candles apart.
+func join(ranges []Range, period market.Period, n int) []Range {
+	var joined []Range
+	for _, r := range ranges {
+		if k := len(joined) - 1; k >= 0 && !r.Start.After(joined[k].End.Add(time.Duration(n+1)*period.Duration())) {
+			if r.End.After(joined[k].End) {
+				joined[k].End = r.End
+			}
+			continue
+		}
+		joined = append(joined, r)
+	}
+	return joined
+}
+
+// mergeRanges returns ranges ordered by start with overlapping and
+// adjoining ones combined.
+func mergeRanges(ranges []Range, period market.Period) []Range {
+	sorted := append([]Range(nil), ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+	return join(sorted, period, 0)
+}
@@ -0,0 +1,147 @@
+package datastore
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+const step = int64(market.Period5m)
+
+// at returns the time of the i-th five minute candle.
+func at(i int64) time.Time { return time.Unix(i*step, 0) }
+
+// r returns the range from candle i to candle j.
+func r(i, j int64) Range { return Range{Start: at(i), End: at(j)} }
+
+// candles returns five minute candles at the given indexes, closing at
+// close.
+func candles(close float64, idx ...int64) []market.Candle {
+	var cs []market.Candle
+	for _, i := range idx {
+		cs = append(cs, market.Candle{Date: i * step, Open: close, High: close, Low: close, Close: close})
+	}
+	return cs
+}
+
+// span returns the indexes i to j.
+func span(i, j int64) []int64 {
+	var idx []int64
+	for ; i <= j; i++ {
+		idx = append(idx, i)
+	}
+	return idx
+}
+
+func formatRanges(ranges []Range) string {
+	s := "["
+	for i, r := range ranges {
+		if i > 0 {
+			s += " "
+		}
+		s += fmt.Sprintf("%d-%d", r.Start.Unix()/step, r.End.Unix()/step)
+	}
+	return s + "]"
+}
+
+func TestMissing(t *testing.T) {
+	tests := []struct {
+		name       string
+		have       []int64
+		start, end time.Time
+		want       []Range
+	}{
+		{"complete", span(0, 4), at(0), at(4), nil},
+		{"empty", nil, at(0), at(4), []Range{r(0, 4)}},
+		{"head", span(2, 4), at(0), at(4), []Range{r(0, 1)}},
+		{"tail", span(0, 2), at(0), at(4), []Range{r(3, 4)}},
+		{"hole", []int64{0, 1, 3, 4}, at(0), at(4), []Range{r(2, 2)}},
+		{"all three", []int64{2, 5, 6}, at(0), at(8), []Range{r(0, 1), r(3, 4), r(7, 8)}},
+		{"unaligned start", span(2, 4), at(0).Add(time.Second), at(4), []Range{r(1, 1)}},
+		{"outside the range", []int64{0, 9}, at(1), at(3), []Range{r(1, 3)}},
+	}
+	for _, tt := range tests {
+		got := Missing(candles(1, tt.have...), market.Period5m, tt.start, tt.end)
+		if formatRanges(got) != formatRanges(tt.want) {
+			t.Errorf("%v: Missing = %v, want %v", tt.name, formatRanges(got), formatRanges(tt.want))
+		}
+	}
+}
+
+func TestSubtract(t *testing.T) {
+	tests := []struct {
+		name  string
+		gaps  []Range
+		holes []Range
+		want  []Range
+	}{
+		{"no holes", []Range{r(0, 10)}, nil, []Range{r(0, 10)}},
+		{"inside", []Range{r(0, 10)}, []Range{r(3, 5)}, []Range{r(0, 2), r(6, 10)}},
+		{"head", []Range{r(0, 10)}, []Range{r(0, 3)}, []Range{r(4, 10)}},
+		{"tail", []Range{r(0, 10)}, []Range{r(8, 12)}, []Range{r(0, 7)}},
+		{"covered", []Range{r(2, 4)}, []Range{r(0, 10)}, nil},
+		{"apart", []Range{r(0, 2)}, []Range{r(3, 5)}, []Range{r(0, 2)}},
+		{"two holes", []Range{r(0, 10), r(20, 30)}, []Range{r(5, 5), r(25, 40)}, []Range{r(0, 4), r(6, 10), r(20, 24)}},
+	}
+	for _, tt := range tests {
+		got := subtract(tt.gaps, tt.holes, market.Period5m)
+		if formatRanges(got) != formatRanges(tt.want) {
+			t.Errorf("%v: subtract = %v, want %v", tt.name, formatRanges(got), formatRanges(tt.want))
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		name   string
+		ranges []Range
+		n      int
+		want   []Range
+	}{
+		{"none", nil, 1, nil},
+		{"adjoining", []Range{r(0, 1), r(2, 3)}, 0, []Range{r(0, 3)}},
+		{"one apart", []Range{r(0, 1), r(3, 4)}, 0, []Range{r(0, 1), r(3, 4)}},
+		{"within n", []Range{r(0, 1), r(3, 4)}, 1, []Range{r(0, 4)}},
+		{"beyond n", []Range{r(0, 1), r(5, 6)}, 2, []Range{r(0, 1), r(5, 6)}},
+		{"contained", []Range{r(0, 10), r(2, 3)}, 0, []Range{r(0, 10)}},
+		{"chain", []Range{r(0, 0), r(2, 2), r(4, 4)}, 1, []Range{r(0, 4)}},
+	}
+	for _, tt := range tests {
+		got := join(tt.ranges, market.Period5m, tt.n)
+		if formatRanges(got) != formatRanges(tt.want) {
+			t.Errorf("%v: join = %v, want %v", tt.name, formatRanges(got), formatRanges(tt.want))
+		}
+	}
+}
+
+func TestMergeRanges(t *testing.T) {
+	got := mergeRanges([]Range{r(10, 12), r(0, 2), r(3, 4), r(11, 15), r(20, 20)}, market.Period5m)
+	if want := []Range{r(0, 4), r(10, 15), r(20, 20)}; formatRanges(got) != formatRanges(want) {
+		t.Errorf("mergeRanges = %v, want %v", formatRanges(got), formatRanges(want))
+	}
+}
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored []int64
+		holes  []Range
+		end    int64
+		want   []Range
+	}{
+		{"complete", span(0, 10), nil, 10, nil},
+		{"nothing stored", nil, nil, 10, []Range{r(0, 10)}},
+		{"close gaps joined", append(span(2, 4), span(6, 10)...), nil, 10, []Range{r(0, 5)}},
+		{"far gaps apart", append(span(1, 1+joinGap), 3+joinGap), nil, 3 + joinGap, []Range{r(0, 0), r(2+joinGap, 2+joinGap)}},
+		{"recorded hole skipped", span(5, 10), []Range{r(0, 4)}, 10, nil},
+		{"hole in a gap", span(5, 8), []Range{r(0, 2)}, 10, []Range{r(3, 10)}},
+	}
+	for _, tt := range tests {
+		got := plan(candles(1, tt.stored...), tt.holes, market.Period5m, at(0), at(tt.end))
+		if formatRanges(got) != formatRanges(tt.want) {
+			t.Errorf("%v: plan = %v, want %v", tt.name, formatRanges(got), formatRanges(tt.want))
+		}
+	}
+}
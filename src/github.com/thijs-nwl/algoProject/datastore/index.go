@@ -0,0 +1,211 @@
+package datastore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+const indexName = "index.json"
+
+// Entry describes one file of the store. Count is zero when the entry was
+// built from the file name rather than written by Save or Reindex.
+type Entry struct {
+	File   string        `json:"file"`
+	Pair   market.Pair   `json:"pair"`
+	Period market.Period `json:"period"`
+	Start  time.Time     `json:"start"`
+	End    time.Time     `json:"end"`
+	Count  int           `json:"count"`
+}
+
+// Overlaps reports whether the entry holds candles between from and to.
+func (e Entry) Overlaps(from, to time.Time) bool {
+	return !e.Start.After(to) && !e.End.Before(from)
+}
+
+// Index lists the entries of the store ordered by pair, period and start.
+// It is read from the index file. Without one it is built from the names
+// of the files in the directory, and the file is only written by the next
+// Save or by Reindex, so reading a store never writes to it.
+func (s *Store) Index() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.index()
+}
+
+// index is Index for callers holding s.mu. Listing the directory and
+// updating the index in Save both happen under the lock, so Save can never
+// write back a list that misses a file saved meanwhile.
+func (s *Store) index() ([]Entry, error) {
+	b, err := ioutil.ReadFile(filepath.Join(s.Dir, indexName))
+	if os.IsNotExist(err) {
+		return s.list()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// list builds the entries of the candle files in the store directory
+// without writing them. Files named by Save carry the dates of their
+// first and last candle, so only legacy files are opened, and those are
+// streamed rather than loaded. Count is left at zero, as counting the
+// candles would mean reading every file.
+func (s *Store) list() ([]Entry, error) {
+	files, err := ioutil.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, f := range files {
+		n, ok := parseName(f.Name())
+		if !ok || f.IsDir() {
+			continue
+		}
+		e := Entry{
+			File:   f.Name(),
+			Pair:   n.pair,
+			Period: n.period,
+			Start:  time.Unix(n.start, 0).UTC(),
+			End:    time.Unix(n.end, 0).UTC(),
+		}
+		if n.legacy {
+			first, last, ok, err := s.span(f.Name())
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			e.Start, e.End = time.Unix(first, 0).UTC(), time.Unix(last, 0).UTC()
+		}
+		entries = append(entries, e)
+	}
+	sortEntries(entries)
+	return entries, nil
+}
+
+// span streams the file name and returns the dates of its first and last
+// candle. It reports false when the file holds none.
+func (s *Store) span(name string) (first, last int64, ok bool, err error) {
+	f, err := os.Open(filepath.Join(s.Dir, name))
+	if err != nil {
+		return 0, 0, false, err
+	}
+	defer f.Close()
+
+	dec := NewDecoder(f)
+	for {
+		c, err := dec.Next()
+		if err == io.EOF {
+			return first, last, ok, nil
+		}
+		if err != nil {
+			return 0, 0, false, fmt.Errorf("datastore: reading %v: %v", name, err)
+		}
+		if !ok || c.Date < first {
+			first = c.Date
+		}
+		if !ok || c.Date > last {
+			last = c.Date
+		}
+		ok = true
+	}
+}
+
+// Reindex rebuilds the index file by reading every candle file in the
+// store directory, counting their candles.
+func (s *Store) Reindex() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := ioutil.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, f := range files {
+		n, ok := parseName(f.Name())
+		if !ok || f.IsDir() {
+			continue
+		}
+		candles, err := s.readFile(f.Name())
+		if err != nil {
+			return nil, err
+		}
+		if len(candles) == 0 {
+			continue
+		}
+		entries = append(entries, newEntry(f.Name(), n.pair, n.period, candles))
+	}
+
+	if err := s.writeIndex(entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Entries returns the index entries of pair at period.
+func (s *Store) Entries(pair market.Pair, period market.Period) ([]Entry, error) {
+	index, err := s.Index()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, e := range index {
+		if e.Pair == pair && e.Period == period {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func (s *Store) writeIndex(entries []Entry) error {
+	sortEntries(entries)
+	b, err := json.MarshalIndent(entries, "", "\t")
+	if err != nil {
+		return err
+	}
+	return s.writeAtomic(indexName, b)
+}
+
+func sortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Pair != b.Pair {
+			return a.Pair.String() < b.Pair.String()
+		}
+		if a.Period != b.Period {
+			return a.Period < b.Period
+		}
+		return a.Start.Before(b.Start)
+	})
+}
+
+func newEntry(file string, pair market.Pair, period market.Period, candles []market.Candle) Entry {
+	return Entry{
+		File:   file,
+		Pair:   pair,
+		Period: period,
+		Start:  time.Unix(candles[0].Date, 0).UTC(),
+		End:    time.Unix(candles[len(candles)-1].Date, 0).UTC(),
+		Count:  len(candles),
+	}
+}
@@ -0,0 +1,68 @@
+package datastore
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// fileName returns the name of the file holding the candles of pair at
+// period between start and end, for example BTC_XMR_300_1512086400_1512088400.json.
+func fileName(pair market.Pair, period market.Period, start, end time.Time) string {
+	return fmt.Sprintf("%v_%v_%v_%v.json", pair, period, start.Unix(), end.Unix())
+}
+
+// holesName returns the name of the file recording the holes of pair at
+// period, for example BTC_XMR_300_holes.json.
+func holesName(pair market.Pair, period market.Period) string {
+	return fmt.Sprintf("%v_%v_holes.json", pair, period)
+}
+
+// parsedName is what a candle file name tells about the file. For names
+// of the current scheme start and end are the dates of the first and last
+// candle; legacy names hold the range that was requested instead.
+type parsedName struct {
+	pair       market.Pair
+	period     market.Period
+	start, end int64
+	legacy     bool
+}
+
+// parseName recovers the pair, period and range from a file name. Besides
+// the current scheme it accepts the older QUOTE_BASE_start_end_ names,
+// which were always downloaded at five minute candles. Any positive period
+// is accepted, so files of periods added later are still found.
+func parseName(name string) (parsedName, bool) {
+	legacy := strings.HasSuffix(name, "_")
+	if !legacy && !strings.HasSuffix(name, ".json") {
+		return parsedName{}, false
+	}
+
+	parts := strings.Split(strings.TrimSuffix(strings.TrimSuffix(name, ".json"), "_"), "_")
+	if legacy && len(parts) == 4 {
+		parts = []string{parts[0], parts[1], market.Period5m.String(), parts[2], parts[3]}
+	}
+	if len(parts) != 5 {
+		return parsedName{}, false
+	}
+
+	var nums [3]int64
+	for i, p := range parts[2:] {
+		n, err := strconv.ParseInt(p, 10, 64)
+		if err != nil {
+			return parsedName{}, false
+		}
+		nums[i] = n
+	}
+	if nums[0] <= 0 {
+		return parsedName{}, false
+	}
+	pair, err := market.ParsePair(parts[0] + "_" + parts[1])
+	if err != nil {
+		return parsedName{}, false
+	}
+	return parsedName{pair: pair, period: market.Period(nums[0]), start: nums[1], end: nums[2], legacy: legacy}, true
+}
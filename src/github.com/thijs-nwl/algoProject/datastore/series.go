@@ -0,0 +1,94 @@
+package datastore
+
+import (
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// Series describes one stored candle series.
+type Series struct {
+	Pair   market.Pair
+	Period market.Period
+	Start  time.Time
+	End    time.Time
+	Count  int
+}
+
+// Series lists the stored series ordered by pair and period. Series kept
+// in overlapping files, or in files the index has no count for, are
+// loaded to count each candle once.
+func (s *Store) Series() ([]Series, error) {
+	index, err := s.Index()
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Series
+	recount := make(map[int]bool)
+	for _, e := range index {
+		n := len(list) - 1
+		if n < 0 || list[n].Pair != e.Pair || list[n].Period != e.Period {
+			list = append(list, Series{Pair: e.Pair, Period: e.Period, Start: e.Start, End: e.End, Count: e.Count})
+			if e.Count == 0 {
+				recount[len(list)-1] = true
+			}
+			continue
+		}
+		if !e.Start.After(list[n].End) || e.Count == 0 {
+			recount[n] = true
+		}
+		if e.End.After(list[n].End) {
+			list[n].End = e.End
+		}
+		list[n].Count += e.Count
+	}
+
+	for i := range recount {
+		candles, err := s.Load(list[i].Pair, list[i].Period, list[i].Start, list[i].End)
+		if err != nil {
+			return nil, err
+		}
+		list[i].Count = len(candles)
+	}
+	return list, nil
+}
+
+// Series lists the series of the database's exchange ordered by pair and
+// period. Series spread over more than one record are read to count each
+// candle once.
+func (db *DB) Series() ([]Series, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var list []Series
+	for _, k := range db.keys() {
+		recs := db.series[k]
+		if k.exchange != db.exchange || len(recs) == 0 {
+			continue
+		}
+		s := Series{Pair: k.pair, Period: k.period, Count: recs[0].count}
+		s.Start, s.End = db.span(k)
+		if len(recs) > 1 {
+			st, err := db.stream(k, s.Start, s.End)
+			if err != nil {
+				return nil, err
+			}
+			if s.Count, err = count(st); err != nil {
+				return nil, err
+			}
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+// count reads the rest of st, closes it and returns the number of candles.
+func count(st *Stream) (int, error) {
+	defer st.Close()
+	n := 0
+	for st.Next() {
+		n++
+	}
+	return n, st.Err()
+}
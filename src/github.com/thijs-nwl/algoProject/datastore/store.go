@@ -0,0 +1,230 @@
+// Package datastore keeps downloaded candle series on disk. It owns the
+// naming of the candle files, keeps an index of the pair, period and time
+// range each file holds, and only fetches the parts of a range that are not
+// stored yet.
+package datastore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// Store is a directory of candle files together with their index. A Store
+// is safe for concurrent use by one process.
+type Store struct {
+	Dir string
+
+	mu sync.Mutex
+}
+
+// New returns a Store rooted at dir.
+func New(dir string) *Store {
+	return &Store{Dir: dir}
+}
+
+// Load returns the stored candles of pair at period between from and to,
+// reading every file that overlaps the range. Candles present in more than
+// one file are returned once.
+func (s *Store) Load(pair market.Pair, period market.Period, from, to time.Time) ([]market.Candle, error) {
+	entries, err := s.Entries(pair, period)
+	if err != nil {
+		return nil, err
+	}
+
+	var series [][]market.Candle
+	for _, e := range entries {
+		if !e.Overlaps(from, to) {
+			continue
+		}
+		candles, err := s.readFile(e.File)
+		if err != nil {
+			return nil, err
+		}
+		series = append(series, candles)
+	}
+
+	var candles []market.Candle
+	for _, c := range market.Merge(series...) {
+		if c.Date >= from.Unix() && c.Date <= to.Unix() {
+			candles = append(candles, c)
+		}
+	}
+	return candles, nil
+}
+
+// Save stores candles of pair at period. They are merged with every file
+// of the series they overlap or adjoin, the new candles winning, and
+// written as one file that replaces those, so a series that grows by
+// updates stays in a single file. The file and the index are both written
+// next to their destination and renamed into place, so readers never see
+// a partial write; the replaced files are removed last. Only the periods
+// of market.Periods are accepted.
+func (s *Store) Save(pair market.Pair, period market.Period, candles []market.Candle) error {
+	if !period.Valid() {
+		return fmt.Errorf("datastore: invalid period %d", int(period))
+	}
+	candles = market.Merge(candles)
+	if len(candles) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index, err := s.index()
+	if err != nil {
+		return err
+	}
+
+	// Merging a file can widen the range enough to reach another one, so
+	// look again until no more files touch it.
+	replaced := make(map[string]bool)
+	for found := true; found; {
+		found = false
+		step := int64(period)
+		from, to := time.Unix(candles[0].Date-step, 0), time.Unix(candles[len(candles)-1].Date+step, 0)
+		for _, e := range index {
+			if e.Pair != pair || e.Period != period || replaced[e.File] || !e.Overlaps(from, to) {
+				continue
+			}
+			old, err := s.readFile(e.File)
+			if err != nil {
+				return err
+			}
+			candles = market.Merge(old, candles)
+			replaced[e.File] = true
+			found = true
+		}
+	}
+
+	first, last := time.Unix(candles[0].Date, 0), time.Unix(candles[len(candles)-1].Date, 0)
+	name := fileName(pair, period, first, last)
+	b, err := json.Marshal(candles)
+	if err != nil {
+		return err
+	}
+	if err := s.writeAtomic(name, b); err != nil {
+		return err
+	}
+
+	entries := []Entry{newEntry(name, pair, period, candles)}
+	for _, e := range index {
+		if e.File != name && !replaced[e.File] {
+			entries = append(entries, e)
+		}
+	}
+	if err := s.writeIndex(entries); err != nil {
+		return err
+	}
+	for file := range replaced {
+		if file != name {
+			if err := os.Remove(filepath.Join(s.Dir, file)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Holes implements Backend. The holes of a series are kept in a file
+// named after it, such as BTC_XMR_300_holes.json.
+func (s *Store) Holes(pair market.Pair, period market.Period) ([]Range, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.holes(pair, period)
+}
+
+func (s *Store) holes(pair market.Pair, period market.Period) ([]Range, error) {
+	var holes []Range
+	err := s.readJSON(holesName(pair, period), &holes)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return holes, err
+}
+
+// SaveHoles implements Backend. The holes are merged with the ones already
+// recorded.
+func (s *Store) SaveHoles(pair market.Pair, period market.Period, holes []Range) error {
+	if len(holes) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, err := s.holes(pair, period)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(mergeRanges(append(old, holes...), period))
+	if err != nil {
+		return err
+	}
+	return s.writeAtomic(holesName(pair, period), b)
+}
+
+// Update makes sure every candle of pair between start and end is stored.
+// See the package function Update. It returns the number of candles that
+// were added.
+func (s *Store) Update(ctx context.Context, src market.CandleSource, pair market.Pair, period market.Period, start, end time.Time) (int, error) {
+	return Update(ctx, s, src, pair, period, start, end)
+}
+
+// Close implements Backend. A Store holds no open files, so it does
+// nothing.
+func (s *Store) Close() error {
+	return nil
+}
+
+func (s *Store) readFile(name string) ([]market.Candle, error) {
+	f, err := os.Open(filepath.Join(s.Dir, name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var candles []market.Candle
+	dec := NewDecoder(f)
+	for {
+		c, err := dec.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("datastore: reading %v: %v", name, err)
+		}
+		candles = append(candles, c)
+	}
+	return market.Merge(candles), nil
+}
+
+func (s *Store) writeAtomic(name string, b []byte) error {
+	tmp, err := ioutil.TempFile(s.Dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.Dir, name))
+}
@@ -0,0 +1,151 @@
+package datastore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+var testPair = market.MustParsePair("BTC_XMR")
+
+// candleFiles returns the names of the candle files in dir.
+func candleFiles(t *testing.T, dir string) []string {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range files {
+		if _, ok := parseName(f.Name()); ok {
+			names = append(names, f.Name())
+		}
+	}
+	return names
+}
+
+func TestStoreSave(t *testing.T) {
+	tests := []struct {
+		name  string
+		saves [][]market.Candle
+		files int
+		want  []market.Candle
+	}{
+		{
+			name:  "one save",
+			saves: [][]market.Candle{candles(1, span(0, 4)...)},
+			files: 1,
+			want:  candles(1, span(0, 4)...),
+		},
+		{
+			name:  "later save wins",
+			saves: [][]market.Candle{candles(1, span(0, 4)...), candles(2, span(3, 6)...)},
+			files: 1,
+			want:  append(candles(1, span(0, 2)...), candles(2, span(3, 6)...)...),
+		},
+		{
+			name:  "adjoining saves combine",
+			saves: [][]market.Candle{candles(1, span(0, 2)...), candles(2, span(3, 5)...)},
+			files: 1,
+			want:  append(candles(1, span(0, 2)...), candles(2, span(3, 5)...)...),
+		},
+		{
+			name:  "apart saves stay apart",
+			saves: [][]market.Candle{candles(1, span(0, 2)...), candles(2, span(5, 6)...)},
+			files: 2,
+			want:  append(candles(1, span(0, 2)...), candles(2, span(5, 6)...)...),
+		},
+		{
+			name:  "bridging save combines all",
+			saves: [][]market.Candle{candles(1, span(0, 2)...), candles(2, span(6, 8)...), candles(3, span(3, 5)...)},
+			files: 1,
+			want:  append(append(candles(1, span(0, 2)...), candles(3, span(3, 5)...)...), candles(2, span(6, 8)...)...),
+		},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		s := New(dir)
+		for _, c := range tt.saves {
+			if err := s.Save(testPair, market.Period5m, c); err != nil {
+				t.Fatalf("%v: %v", tt.name, err)
+			}
+		}
+		if files := candleFiles(t, dir); len(files) != tt.files {
+			t.Errorf("%v: files %v, want %d", tt.name, files, tt.files)
+		}
+		got, err := s.Load(testPair, market.Period5m, at(0), at(100))
+		if err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		if !sameCandles(got, tt.want) {
+			t.Errorf("%v: loaded %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStoreSaveInvalidPeriod(t *testing.T) {
+	s := New(t.TempDir())
+	if err := s.Save(testPair, 7, candles(1, 0)); err == nil {
+		t.Error("Save with period 7 succeeded, want an error")
+	}
+}
+
+func TestStoreIndexFromNames(t *testing.T) {
+	dir := t.TempDir()
+	if err := New(dir).Save(testPair, market.Period5m, candles(1, 0, 1, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, indexName)); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(dir)
+	entries, err := s.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || !entries[0].Start.Equal(at(0)) || !entries[0].End.Equal(at(3)) {
+		t.Fatalf("Index = %+v, want one entry from candle 0 to 3", entries)
+	}
+	if _, err := os.Stat(filepath.Join(dir, indexName)); !os.IsNotExist(err) {
+		t.Errorf("Index wrote %v, want it left alone", indexName)
+	}
+
+	series, err := s.Series()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != 1 || series[0].Count != 3 {
+		t.Errorf("Series = %+v, want one series of 3 candles", series)
+	}
+}
+
+func TestStoreHoles(t *testing.T) {
+	s := New(t.TempDir())
+	for _, holes := range [][]Range{{r(0, 2)}, {r(3, 4), r(10, 12)}} {
+		if err := s.SaveHoles(testPair, market.Period5m, holes); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := s.Holes(testPair, market.Period5m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Range{r(0, 4), r(10, 12)}; formatRanges(got) != formatRanges(want) {
+		t.Errorf("Holes = %v, want %v", formatRanges(got), formatRanges(want))
+	}
+}
+
+func sameCandles(a, b []market.Candle) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
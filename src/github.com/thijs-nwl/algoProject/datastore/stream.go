@@ -0,0 +1,188 @@
+package datastore
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// Decoder reads the candles of a JSON array one at a time, so files of
+// any size are read in constant memory.
+type Decoder struct {
+	dec     *json.Decoder
+	started bool
+}
+
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{dec: json.NewDecoder(bufio.NewReader(r))}
+}
+
+// Next returns the next candle, or io.EOF after the last one.
+func (d *Decoder) Next() (market.Candle, error) {
+	if !d.started {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return market.Candle{}, err
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return market.Candle{}, fmt.Errorf("datastore: want a candle array, found %v", tok)
+		}
+		d.started = true
+	}
+
+	if !d.dec.More() {
+		if _, err := d.dec.Token(); err != nil {
+			return market.Candle{}, err
+		}
+		return market.Candle{}, io.EOF
+	}
+
+	var c market.Candle
+	err := d.dec.Decode(&c)
+	return c, err
+}
+
+// Stream iterates over the stored candles of a series in date order. It
+// keeps one candle per file, or per database record, in memory and merges
+// them as it goes. Where they overlap, each date is yielded once, taken
+// from the source saved last.
+type Stream struct {
+	from, to int64
+	sources  []*streamSource
+	closers  []io.Closer
+	cur      market.Candle
+	err      error
+}
+
+// streamSource is one sorted run of candles, read by next until io.EOF.
+type streamSource struct {
+	name string
+	next func() (market.Candle, error)
+	head market.Candle
+	ok   bool
+}
+
+func newStream(from, to time.Time) *Stream {
+	return &Stream{from: from.Unix(), to: to.Unix()}
+}
+
+// add appends a source; sources added later win over earlier ones.
+func (st *Stream) add(name string, next func() (market.Candle, error)) error {
+	src := &streamSource{name: name, next: next}
+	st.sources = append(st.sources, src)
+	return st.advance(src)
+}
+
+// Stream opens the stored candles of pair at period between from and to
+// for reading one at a time. Close the stream when done.
+func (s *Store) Stream(pair market.Pair, period market.Period, from, to time.Time) (*Stream, error) {
+	entries, err := s.Entries(pair, period)
+	if err != nil {
+		return nil, err
+	}
+
+	st := newStream(from, to)
+	for _, e := range entries {
+		if !e.Overlaps(from, to) {
+			continue
+		}
+		f, err := os.Open(filepath.Join(s.Dir, e.File))
+		if err != nil {
+			st.Close()
+			return nil, err
+		}
+		st.closers = append(st.closers, f)
+		if err := st.add(f.Name(), NewDecoder(f).Next); err != nil {
+			st.Close()
+			return nil, err
+		}
+	}
+	return st, nil
+}
+
+// advance moves src to its next candle within the range.
+func (st *Stream) advance(src *streamSource) error {
+	prev, had := src.head.Date, src.ok
+	for {
+		c, err := src.next()
+		if err == io.EOF || err == nil && c.Date > st.to {
+			src.ok = false
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("datastore: reading %v: %v", src.name, err)
+		}
+		if had && c.Date < prev {
+			return fmt.Errorf("datastore: %v is not sorted by date at %v", src.name, c.Date)
+		}
+		if c.Date >= st.from {
+			src.head, src.ok = c, true
+			return nil
+		}
+	}
+}
+
+// Next advances to the next candle. It returns false at the end of the
+// range or on an error.
+func (st *Stream) Next() bool {
+	if st.err != nil {
+		return false
+	}
+
+	var next *streamSource
+	for _, src := range st.sources {
+		if src.ok && (next == nil || src.head.Date <= next.head.Date) {
+			next = src
+		}
+	}
+	if next == nil {
+		return false
+	}
+	st.cur = next.head
+
+	// Skip the same date in every source, the later source having won
+	// above.
+	for _, src := range st.sources {
+		for src.ok && src.head.Date == st.cur.Date {
+			if err := st.advance(src); err != nil {
+				st.err = err
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Candle returns the current candle.
+func (st *Stream) Candle() market.Candle { return st.cur }
+
+// Err returns the first error met while reading.
+func (st *Stream) Err() error { return st.err }
+
+// Close closes the files of the stream.
+func (st *Stream) Close() error {
+	var first error
+	for _, c := range st.closers {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// collect reads the rest of st into a slice and closes it.
+func collect(st *Stream) ([]market.Candle, error) {
+	defer st.Close()
+	var candles []market.Candle
+	for st.Next() {
+		candles = append(candles, st.Candle())
+	}
+	return candles, st.Err()
+}
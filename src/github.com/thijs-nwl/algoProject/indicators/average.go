@@ -0,0 +1,99 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// SMA is the simple moving average of the last N closes.
+type SMA struct {
+	w   *window
+	sum float64
+}
+
+// NewSMA returns an SMA over n values.
+func NewSMA(n int) *SMA {
+	return &SMA{w: newWindow(n)}
+}
+
+// Next implements Indicator.
+func (s *SMA) Next(c market.Candle) float64 { return s.Add(c.Close) }
+
+// Add feeds a value other than a close and returns the average.
+func (s *SMA) Add(v float64) float64 {
+	old, full := s.w.push(v)
+	s.sum += v
+	if full {
+		s.sum -= old
+	}
+	if !s.w.full {
+		return math.NaN()
+	}
+	return s.sum / float64(len(s.w.values))
+}
+
+// EMA is the exponential moving average of the closes with smoothing
+// 2/(N+1). It starts from the simple average of the first N values.
+type EMA struct {
+	n     int
+	alpha float64
+	seed  float64
+	count int
+	value float64
+}
+
+// NewEMA returns an EMA over n values.
+func NewEMA(n int) *EMA {
+	if n < 1 {
+		n = 1
+	}
+	return &EMA{n: n, alpha: 2 / float64(n+1)}
+}
+
+// Next implements Indicator.
+func (e *EMA) Next(c market.Candle) float64 { return e.Add(c.Close) }
+
+// Add feeds a value other than a close and returns the average.
+func (e *EMA) Add(v float64) float64 {
+	e.count++
+	switch {
+	case e.count < e.n:
+		e.seed += v
+		return math.NaN()
+	case e.count == e.n:
+		e.value = (e.seed + v) / float64(e.n)
+	default:
+		e.value += e.alpha * (v - e.value)
+	}
+	return e.value
+}
+
+// WMA is the linearly weighted moving average of the last N closes, the
+// newest weighing N and the oldest 1.
+type WMA struct {
+	w *window
+}
+
+// NewWMA returns a WMA over n values.
+func NewWMA(n int) *WMA {
+	return &WMA{w: newWindow(n)}
+}
+
+// Next implements Indicator.
+func (w *WMA) Next(c market.Candle) float64 { return w.Add(c.Close) }
+
+// Add feeds a value other than a close and returns the average.
+func (w *WMA) Add(v float64) float64 {
+	w.w.push(v)
+	if !w.w.full {
+		return math.NaN()
+	}
+
+	n := len(w.w.values)
+	var sum float64
+	for i := 0; i < n; i++ {
+		sum += float64(i+1) * w.w.at(i)
+	}
+	return sum / float64(n*(n+1)/2)
+}
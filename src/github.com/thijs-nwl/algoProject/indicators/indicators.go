@@ -0,0 +1,94 @@
+// Package indicators computes technical indicators over candle series.
+//
+// Every indicator is a streaming type fed one candle at a time through
+// Next, so it can follow a live feed as well as a stored history. Values
+// are NaN until the indicator has seen enough candles to be defined. The
+// Run functions apply an indicator to a whole series at once and return
+// one value per candle.
+package indicators
+
+import (
+	"math"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// Indicator is a streaming indicator with a single value per candle.
+type Indicator interface {
+	Next(c market.Candle) float64
+}
+
+// Run feeds candles to ind and returns its value after each of them.
+func Run(candles []market.Candle, ind Indicator) []float64 {
+	values := make([]float64, len(candles))
+	for i, c := range candles {
+		values[i] = ind.Next(c)
+	}
+	return values
+}
+
+// Closes returns the close of every candle.
+func Closes(candles []market.Candle) []float64 {
+	closes := make([]float64, len(candles))
+	for i, c := range candles {
+		closes[i] = c.Close
+	}
+	return closes
+}
+
+// window holds the last n values added to it.
+type window struct {
+	values []float64
+	next   int
+	full   bool
+}
+
+func newWindow(n int) *window {
+	if n < 1 {
+		n = 1
+	}
+	return &window{values: make([]float64, n)}
+}
+
+// push adds v and returns the value it pushed out, if the window was full.
+func (w *window) push(v float64) (float64, bool) {
+	old, full := w.values[w.next], w.full
+	w.values[w.next] = v
+	w.next++
+	if w.next == len(w.values) {
+		w.next = 0
+		w.full = true
+	}
+	return old, full
+}
+
+// at returns the i-th value, oldest first.
+func (w *window) at(i int) float64 {
+	if !w.full {
+		return w.values[i]
+	}
+	return w.values[(w.next+i)%len(w.values)]
+}
+
+func (w *window) len() int {
+	if w.full {
+		return len(w.values)
+	}
+	return w.next
+}
+
+func (w *window) max() float64 {
+	m := math.Inf(-1)
+	for i := 0; i < w.len(); i++ {
+		m = math.Max(m, w.at(i))
+	}
+	return m
+}
+
+func (w *window) min() float64 {
+	m := math.Inf(1)
+	for i := 0; i < w.len(); i++ {
+		m = math.Min(m, w.at(i))
+	}
+	return m
+}
@@ -0,0 +1,173 @@
+package indicators
+
+import (
+	"io"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/thijs-nwl/algoProject/datastore"
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// testCandles returns the first 500 five minute candles of the BTC_XMR
+// history kept in the datastore directory, which have no gaps.
+func testCandles(t *testing.T) []market.Candle {
+	f, err := os.Open("../datastore/BTC_XMR_1512086400_1516406400_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var candles []market.Candle
+	dec := datastore.NewDecoder(f)
+	for {
+		c, err := dec.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		candles = append(candles, c)
+	}
+	candles = market.Merge(candles)
+	if len(candles) < 500 {
+		t.Fatalf("got %d candles, want at least 500", len(candles))
+	}
+	return candles[:500]
+}
+
+// goldenTest is an indicator series with values known at some candles.
+// batch computes the whole series with the Run functions; next returns a
+// fresh indicator to be fed one candle at a time.
+type goldenTest struct {
+	name  string
+	batch func(candles []market.Candle) []float64
+	next  func() func(market.Candle) float64
+	want  map[int]float64
+}
+
+// The golden values were computed apart from this package, from the
+// textbook definitions: Wilder smoothing for RSI and ATR, EMAs seeded with
+// the simple average of their first N values, the population deviation
+// for Bollinger Bands and a VWAP that starts over at midnight UTC. Candle
+// 288 is the first of the second day.
+func goldenTests() []goldenTest {
+	nan := math.NaN()
+	single := func(name string, ind func() Indicator, want map[int]float64) goldenTest {
+		return goldenTest{
+			name:  name,
+			batch: func(candles []market.Candle) []float64 { return Run(candles, ind()) },
+			next:  func() func(market.Candle) float64 { return ind().Next },
+			want:  want,
+		}
+	}
+	macd := func(name string, pick func(MACDValue) float64, want map[int]float64) goldenTest {
+		return goldenTest{
+			name: name,
+			batch: func(candles []market.Candle) []float64 {
+				var out []float64
+				for _, v := range RunMACD(candles, NewMACD(12, 26, 9)) {
+					out = append(out, pick(v))
+				}
+				return out
+			},
+			next: func() func(market.Candle) float64 {
+				m := NewMACD(12, 26, 9)
+				return func(c market.Candle) float64 { return pick(m.Next(c)) }
+			},
+			want: want,
+		}
+	}
+	bollinger := func(name string, pick func(Band) float64, want map[int]float64) goldenTest {
+		return goldenTest{
+			name: name,
+			batch: func(candles []market.Candle) []float64 {
+				var out []float64
+				for _, b := range RunBollinger(candles, NewBollinger(20, 2)) {
+					out = append(out, pick(b))
+				}
+				return out
+			},
+			next: func() func(market.Candle) float64 {
+				b := NewBollinger(20, 2)
+				return func(c market.Candle) float64 { return pick(b.Next(c)) }
+			},
+			want: want,
+		}
+	}
+	stochastic := func(name string, pick func(StochasticValue) float64, want map[int]float64) goldenTest {
+		return goldenTest{
+			name: name,
+			batch: func(candles []market.Candle) []float64 {
+				var out []float64
+				for _, s := range RunStochastic(candles, NewStochastic(14, 3)) {
+					out = append(out, pick(s))
+				}
+				return out
+			},
+			next: func() func(market.Candle) float64 {
+				s := NewStochastic(14, 3)
+				return func(c market.Candle) float64 { return pick(s.Next(c)) }
+			},
+			want: want,
+		}
+	}
+
+	return []goldenTest{
+		single("SMA(20)", func() Indicator { return NewSMA(20) }, map[int]float64{18: nan, 19: 0.017464697499999994, 100: 0.0177910615, 288: 0.017414558, 499: 0.0171532205}),
+		single("EMA(20)", func() Indicator { return NewEMA(20) }, map[int]float64{18: nan, 19: 0.017464697499999994, 100: 0.017768357086135923, 288: 0.017389384729112254, 499: 0.0171551958909433}),
+		single("WMA(20)", func() Indicator { return NewWMA(20) }, map[int]float64{18: nan, 19: 0.01741870080952381, 100: 0.01780079595238095, 288: 0.017400432809523813, 499: 0.017151768857142858}),
+		single("RSI(14)", func() Indicator { return NewRSI(14) }, map[int]float64{13: nan, 14: 42.11033258418482, 15: 37.73799356323766, 100: 55.72068353439107, 499: 52.24323716608589}),
+		macd("MACD(12,26,9)", func(v MACDValue) float64 { return v.MACD }, map[int]float64{24: nan, 25: -7.901049260076315e-05, 100: 4.663970421488889e-05, 499: -1.2257920173570913e-05}),
+		macd("MACD signal", func(v MACDValue) float64 { return v.Signal }, map[int]float64{32: nan, 33: -6.820280405936292e-05, 100: 5.4412720863013995e-05, 499: -8.394323825469274e-06}),
+		macd("MACD histogram", func(v MACDValue) float64 { return v.Histogram }, map[int]float64{32: nan, 33: 9.710475158719825e-06, 100: -7.773016648125107e-06, 499: -3.863596348101639e-06}),
+		bollinger("Bollinger middle", func(b Band) float64 { return b.Middle }, map[int]float64{18: nan, 19: 0.017464697499999994, 499: 0.0171532205}),
+		bollinger("Bollinger upper", func(b Band) float64 { return b.Upper }, map[int]float64{18: nan, 19: 0.01769140342730451, 100: 0.01787039127632012, 499: 0.01725622835124931}),
+		bollinger("Bollinger lower", func(b Band) float64 { return b.Lower }, map[int]float64{18: nan, 19: 0.01723799157269548, 100: 0.01771173172367988, 499: 0.01705021264875069}),
+		single("ATR(14)", func() Indicator { return NewATR(14) }, map[int]float64{12: nan, 13: 0.00013143714285714266, 14: 0.00013447734693877535, 100: 6.937904005177827e-05, 499: 0.00010701491750540718}),
+		stochastic("Stochastic %K", func(s StochasticValue) float64 { return s.K }, map[int]float64{12: nan, 13: 58.78462570689238, 15: 0, 100: 69.76728634479045, 499: 79.93599730514978}),
+		stochastic("Stochastic %D", func(s StochasticValue) float64 { return s.D }, map[int]float64{14: nan, 15: 26.612138832976736, 100: 69.77397351879107, 499: 50.5012033521287}),
+		single("OBV", func() Indicator { return NewOBV() }, map[int]float64{0: 0, 13: -260.22801062, 287: 8164.438738840004, 499: 8390.220625470007}),
+		single("VWAP daily", func() Indicator { return NewVWAP(market.Period1d) }, map[int]float64{0: 0.01758572310718653, 287: 0.017573089209543178, 288: 0.017397719187071913, 499: 0.017214442233864334}),
+	}
+}
+
+func TestGolden(t *testing.T) {
+	candles := testCandles(t)
+	for _, tt := range goldenTests() {
+		values := tt.batch(candles)
+		for i, want := range tt.want {
+			if got := values[i]; !near(got, want) {
+				t.Errorf("%v at candle %d = %v, want %v", tt.name, i, got, want)
+			}
+		}
+	}
+}
+
+// TestGoldenNext feeds a fresh indicator one candle at a time from an
+// iterator, the way a live feed does, and checks it against the golden
+// values as each candle arrives.
+func TestGoldenNext(t *testing.T) {
+	candles := testCandles(t)
+	for _, tt := range goldenTests() {
+		next := tt.next()
+		it := market.NewSliceIterator(candles)
+		for i := 0; it.Next(); i++ {
+			got := next(it.Candle())
+			if want, ok := tt.want[i]; ok && !near(got, want) {
+				t.Errorf("%v: Next at candle %d = %v, want %v", tt.name, i, got, want)
+			}
+		}
+	}
+}
+
+// near reports whether got equals want up to rounding, counting two NaNs
+// as equal.
+func near(got, want float64) bool {
+	if math.IsNaN(want) {
+		return math.IsNaN(got)
+	}
+	return math.Abs(got-want) <= 1e-9*math.Abs(want)
+}
@@ -0,0 +1,139 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// RSI is Wilder's relative strength index over N changes of the close.
+type RSI struct {
+	n       int
+	count   int
+	prev    float64
+	avgGain float64
+	avgLoss float64
+}
+
+// NewRSI returns an RSI over n changes.
+func NewRSI(n int) *RSI {
+	if n < 1 {
+		n = 1
+	}
+	return &RSI{n: n}
+}
+
+// Next implements Indicator.
+func (r *RSI) Next(c market.Candle) float64 {
+	r.count++
+	if r.count == 1 {
+		r.prev = c.Close
+		return math.NaN()
+	}
+
+	change := c.Close - r.prev
+	r.prev = c.Close
+	gain, loss := math.Max(change, 0), math.Max(-change, 0)
+
+	n := float64(r.n)
+	if r.count <= r.n+1 {
+		r.avgGain += gain / n
+		r.avgLoss += loss / n
+		if r.count <= r.n {
+			return math.NaN()
+		}
+	} else {
+		r.avgGain = (r.avgGain*(n-1) + gain) / n
+		r.avgLoss = (r.avgLoss*(n-1) + loss) / n
+	}
+
+	if r.avgLoss == 0 {
+		return 100
+	}
+	return 100 - 100/(1+r.avgGain/r.avgLoss)
+}
+
+// MACDValue is the output of MACD for one candle.
+type MACDValue struct {
+	MACD      float64
+	Signal    float64
+	Histogram float64
+}
+
+// MACD is the moving average convergence divergence: the difference of a
+// fast and a slow EMA of the close, with an EMA of that difference as the
+// signal line.
+type MACD struct {
+	fast, slow, signal *EMA
+}
+
+// NewMACD returns a MACD with the given EMA lengths, classically 12, 26
+// and 9.
+func NewMACD(fast, slow, signal int) *MACD {
+	return &MACD{fast: NewEMA(fast), slow: NewEMA(slow), signal: NewEMA(signal)}
+}
+
+// Next returns the MACD after c.
+func (m *MACD) Next(c market.Candle) MACDValue {
+	macd := m.fast.Next(c) - m.slow.Next(c)
+	if math.IsNaN(macd) {
+		return MACDValue{math.NaN(), math.NaN(), math.NaN()}
+	}
+	signal := m.signal.Add(macd)
+	return MACDValue{MACD: macd, Signal: signal, Histogram: macd - signal}
+}
+
+// RunMACD feeds candles to m and returns its value after each of them.
+func RunMACD(candles []market.Candle, m *MACD) []MACDValue {
+	values := make([]MACDValue, len(candles))
+	for i, c := range candles {
+		values[i] = m.Next(c)
+	}
+	return values
+}
+
+// StochasticValue is the output of Stochastic for one candle.
+type StochasticValue struct {
+	K float64
+	D float64
+}
+
+// Stochastic is the stochastic oscillator: %K places the close within the
+// high-low range of the last N candles, %D is a simple average of %K. A
+// flat range gives a %K of 50.
+type Stochastic struct {
+	highs, lows *window
+	d           *SMA
+}
+
+// NewStochastic returns a stochastic oscillator over k candles with %D
+// averaged over d values, classically 14 and 3.
+func NewStochastic(k, d int) *Stochastic {
+	return &Stochastic{highs: newWindow(k), lows: newWindow(k), d: NewSMA(d)}
+}
+
+// Next returns the oscillator after c.
+func (s *Stochastic) Next(c market.Candle) StochasticValue {
+	s.highs.push(c.High)
+	s.lows.push(c.Low)
+	if !s.highs.full {
+		return StochasticValue{math.NaN(), math.NaN()}
+	}
+
+	hh, ll := s.highs.max(), s.lows.min()
+	k := 50.0
+	if hh > ll {
+		k = 100 * (c.Close - ll) / (hh - ll)
+	}
+	return StochasticValue{K: k, D: s.d.Add(k)}
+}
+
+// RunStochastic feeds candles to s and returns its value after each of
+// them.
+func RunStochastic(candles []market.Candle, s *Stochastic) []StochasticValue {
+	values := make([]StochasticValue, len(candles))
+	for i, c := range candles {
+		values[i] = s.Next(c)
+	}
+	return values
+}
@@ -0,0 +1,97 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// Band is the output of Bollinger for one candle.
+type Band struct {
+	Middle float64
+	Upper  float64
+	Lower  float64
+}
+
+// Bollinger is a band of K standard deviations around the simple moving
+// average of the last N closes.
+type Bollinger struct {
+	w *window
+	k float64
+}
+
+// NewBollinger returns Bollinger Bands over n closes, k deviations wide,
+// classically 20 and 2.
+func NewBollinger(n int, k float64) *Bollinger {
+	return &Bollinger{w: newWindow(n), k: k}
+}
+
+// Next returns the bands after c.
+func (b *Bollinger) Next(c market.Candle) Band {
+	b.w.push(c.Close)
+	if !b.w.full {
+		return Band{math.NaN(), math.NaN(), math.NaN()}
+	}
+
+	n := float64(b.w.len())
+	var sum, sq float64
+	for i := 0; i < b.w.len(); i++ {
+		sum += b.w.at(i)
+	}
+	mean := sum / n
+	for i := 0; i < b.w.len(); i++ {
+		d := b.w.at(i) - mean
+		sq += d * d
+	}
+	dev := b.k * math.Sqrt(sq/n)
+	return Band{Middle: mean, Upper: mean + dev, Lower: mean - dev}
+}
+
+// RunBollinger feeds candles to b and returns the bands after each of
+// them.
+func RunBollinger(candles []market.Candle, b *Bollinger) []Band {
+	values := make([]Band, len(candles))
+	for i, c := range candles {
+		values[i] = b.Next(c)
+	}
+	return values
+}
+
+// ATR is Wilder's average true range over N candles.
+type ATR struct {
+	n       int
+	count   int
+	prev    float64
+	sum     float64
+	average float64
+}
+
+// NewATR returns an ATR over n candles.
+func NewATR(n int) *ATR {
+	if n < 1 {
+		n = 1
+	}
+	return &ATR{n: n}
+}
+
+// Next implements Indicator.
+func (a *ATR) Next(c market.Candle) float64 {
+	tr := c.High - c.Low
+	if a.count > 0 {
+		tr = math.Max(tr, math.Max(math.Abs(c.High-a.prev), math.Abs(c.Low-a.prev)))
+	}
+	a.prev = c.Close
+	a.count++
+
+	n := float64(a.n)
+	switch {
+	case a.count < a.n:
+		a.sum += tr
+		return math.NaN()
+	case a.count == a.n:
+		a.average = (a.sum + tr) / n
+	default:
+		a.average = (a.average*(n-1) + tr) / n
+	}
+	return a.average
+}
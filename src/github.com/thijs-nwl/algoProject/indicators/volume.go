@@ -0,0 +1,69 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// OBV is the on-balance volume: the running total of the base currency
+// volume, added on candles that close higher and subtracted on candles
+// that close lower.
+type OBV struct {
+	started bool
+	prev    float64
+	total   float64
+}
+
+// NewOBV returns an OBV starting at zero.
+func NewOBV() *OBV {
+	return &OBV{}
+}
+
+// Next implements Indicator.
+func (o *OBV) Next(c market.Candle) float64 {
+	if o.started {
+		switch {
+		case c.Close > o.prev:
+			o.total += c.QuoteVolume
+		case c.Close < o.prev:
+			o.total -= c.QuoteVolume
+		}
+	}
+	o.started = true
+	o.prev = c.Close
+	return o.total
+}
+
+// VWAP is the volume weighted average price since the last anchor. It is
+// computed from the traded volumes themselves rather than from typical
+// prices, so it is exact for the candles seen.
+type VWAP struct {
+	anchor int64
+	start  int64
+	volume float64
+	base   float64
+}
+
+// NewVWAP returns a VWAP that starts over at every multiple of anchor
+// since the Unix epoch, such as market.Period1d for a daily VWAP. An anchor
+// of zero never starts over.
+func NewVWAP(anchor market.Period) *VWAP {
+	return &VWAP{anchor: int64(anchor), start: math.MinInt64}
+}
+
+// Next implements Indicator.
+func (v *VWAP) Next(c market.Candle) float64 {
+	if v.anchor > 0 {
+		if start := c.Date - c.Date%v.anchor; start != v.start {
+			v.start, v.volume, v.base = start, 0, 0
+		}
+	}
+
+	v.volume += c.Volume
+	v.base += c.QuoteVolume
+	if v.base == 0 {
+		return math.NaN()
+	}
+	return v.volume / v.base
+}
@@ -0,0 +1,163 @@
+// Package kraken reads candles from the Kraken OHLC endpoint.
+package kraken
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+	"github.com/thijs-nwl/algoProject/request"
+)
+
+// DefaultBaseURL is the public API endpoint of Kraken.
+const DefaultBaseURL = "https://api.kraken.com"
+
+// Kraken has no two hour interval.
+var intervals = map[market.Period]int{
+	market.Period5m:  5,
+	market.Period15m: 15,
+	market.Period30m: 30,
+	market.Period4h:  240,
+	market.Period1d:  1440,
+}
+
+// Client fetches OHLC data from the Kraken public API.
+type Client struct {
+	BaseURL  string
+	Requests *request.Client
+}
+
+// NewClient returns a Client for the live Kraken API.
+func NewClient() *Client {
+	r := request.New()
+	r.RequestsPerSecond = 1
+	return &Client{BaseURL: DefaultBaseURL, Requests: r}
+}
+
+// APIError holds the error strings of a Kraken response.
+type APIError struct {
+	Messages []string
+}
+
+func (e *APIError) Error() string {
+	return "kraken: " + strings.Join(e.Messages, "; ")
+}
+
+type ohlcResponse struct {
+	Error  []string                   `json:"error"`
+	Result map[string]json.RawMessage `json:"result"`
+}
+
+// FetchCandles implements market.CandleSource. Kraken only serves the most
+// recent 720 candles of an interval, so older parts of a range come back
+// empty.
+func (c *Client) FetchCandles(ctx context.Context, pair market.Pair, period market.Period, from, to time.Time) ([]market.Candle, error) {
+	interval, ok := intervals[period]
+	if !ok {
+		return nil, fmt.Errorf("kraken: unsupported period %v", period)
+	}
+
+	var candles []market.Candle
+	for since := from.Unix() - 1; since < to.Unix(); {
+		page, last, err := c.ohlc(ctx, pair, interval, since)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range page {
+			if c.Date >= from.Unix() && c.Date <= to.Unix() {
+				candles = append(candles, c)
+			}
+		}
+		if len(page) == 0 || last <= since {
+			break
+		}
+		since = last
+	}
+
+	if len(candles) == 0 {
+		return nil, market.ErrNoData
+	}
+	return market.Merge(candles), nil
+}
+
+func (c *Client) ohlc(ctx context.Context, pair market.Pair, interval int, since int64) ([]market.Candle, int64, error) {
+	q := url.Values{}
+	q.Set("pair", pair.Symbol(market.Kraken))
+	q.Set("interval", strconv.Itoa(interval))
+	q.Set("since", strconv.FormatInt(since, 10))
+
+	body, err := c.Requests.Get(ctx, c.BaseURL+"/0/public/OHLC?"+q.Encode())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var res ohlcResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, 0, fmt.Errorf("kraken: decoding OHLC: %v", err)
+	}
+	if len(res.Error) > 0 {
+		return nil, 0, &APIError{Messages: res.Error}
+	}
+
+	var last int64
+	var rows [][]interface{}
+	for key, raw := range res.Result {
+		var err error
+		if key == "last" {
+			err = json.Unmarshal(raw, &last)
+		} else {
+			err = json.Unmarshal(raw, &rows)
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("kraken: decoding OHLC %v: %v", key, err)
+		}
+	}
+
+	candles := make([]market.Candle, 0, len(rows))
+	for _, row := range rows {
+		c, err := parseRow(row)
+		if err != nil {
+			return nil, 0, err
+		}
+		candles = append(candles, c)
+	}
+	return candles, last, nil
+}
+
+// parseRow converts an OHLC row [time, open, high, low, close, vwap,
+// volume, count]. Kraken counts volume in the base currency.
+func parseRow(row []interface{}) (market.Candle, error) {
+	if len(row) < 7 {
+		return market.Candle{}, fmt.Errorf("kraken: OHLC row has %d fields, want at least 7", len(row))
+	}
+	t, ok := row[0].(float64)
+	if !ok {
+		return market.Candle{}, fmt.Errorf("kraken: invalid OHLC time %v", row[0])
+	}
+
+	var f [7]float64
+	for i := 1; i < 7; i++ {
+		s, _ := row[i].(string)
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return market.Candle{}, fmt.Errorf("kraken: invalid OHLC field %d: %v", i, row[i])
+		}
+		f[i] = v
+	}
+
+	return market.Candle{
+		Date:            int64(t),
+		Open:            f[1],
+		High:            f[2],
+		Low:             f[3],
+		Close:           f[4],
+		WeightedAverage: f[5],
+		QuoteVolume:     f[6],
+		Volume:          f[5] * f[6],
+	}, nil
+}
@@ -0,0 +1,96 @@
+package market
+
+import "sort"
+
+// Aggregator builds candles from a stream of trades. The candles follow
+// the exchange's chart data: Date is the start of the period, Volume sums
+// the quote currency totals and QuoteVolume the base currency amounts, and
+// WeightedAverage is their ratio. A period without trades gets a flat
+// candle at the previous close with no volume.
+type Aggregator struct {
+	step    int64
+	cur     Candle
+	started bool
+}
+
+// NewAggregator returns an Aggregator for candles of period.
+func NewAggregator(period Period) *Aggregator {
+	return &Aggregator{step: int64(period)}
+}
+
+// Add feeds the next trade and returns the candles it completed. Trades
+// should come in date order; a trade older than the candle in progress is
+// counted in that candle.
+func (a *Aggregator) Add(t Trade) []Candle {
+	start := t.Date - mod(t.Date, a.step)
+	if !a.started {
+		a.open(start, t.Price)
+	}
+
+	var done []Candle
+	for a.cur.Date < start {
+		done = append(done, a.finish())
+		a.open(a.cur.Date+a.step, a.cur.Close)
+	}
+
+	total := t.Total
+	if total == 0 {
+		total = t.Price * t.Amount
+	}
+	if a.cur.QuoteVolume == 0 {
+		a.cur.Open, a.cur.High, a.cur.Low = t.Price, t.Price, t.Price
+	}
+	if t.Price > a.cur.High {
+		a.cur.High = t.Price
+	}
+	if t.Price < a.cur.Low {
+		a.cur.Low = t.Price
+	}
+	a.cur.Close = t.Price
+	a.cur.Volume += total
+	a.cur.QuoteVolume += t.Amount
+	return done
+}
+
+// Flush returns the candle in progress, which may be incomplete. It
+// reports false when no trade has been added.
+func (a *Aggregator) Flush() (Candle, bool) {
+	if !a.started {
+		return Candle{}, false
+	}
+	c := a.cur
+	if c.QuoteVolume > 0 {
+		c.WeightedAverage = c.Volume / c.QuoteVolume
+	}
+	return c, true
+}
+
+// open starts an empty candle at date, flat at price.
+func (a *Aggregator) open(date int64, price float64) {
+	a.cur = Candle{Date: date, Open: price, High: price, Low: price, Close: price, WeightedAverage: price}
+	a.started = true
+}
+
+func (a *Aggregator) finish() Candle {
+	c, _ := a.Flush()
+	return c
+}
+
+// CandlesFromTrades builds the candles of period covering trades, which
+// may be in any order. Every trade is counted; use MergeTrades first to
+// drop trades fetched twice. The last candle holds the trades after the
+// last full period and may be incomplete.
+func CandlesFromTrades(trades []Trade, period Period) []Candle {
+	sorted := append([]Trade(nil), trades...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date < sorted[j].Date })
+
+	a := NewAggregator(period)
+	var candles []Candle
+	for _, t := range sorted {
+		candles = append(candles, a.Add(t)...)
+	}
+	if c, ok := a.Flush(); ok {
+		candles = append(candles, c)
+	}
+	return candles
+}
@@ -0,0 +1,26 @@
+package market
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCandlesFromTrades(t *testing.T) {
+	trades := []Trade{
+		{Date: 1210, Side: Sell, Price: 4, Amount: 1},
+		{Date: 702, Side: Buy, Price: 1, Amount: 8},
+		{Date: 701, Side: Buy, Price: 2, Amount: 2.5},
+		{Date: 702, Side: Buy, Price: 1, Amount: 8},
+	}
+	want := []Candle{
+		{Date: 600, Open: 2, High: 2, Low: 1, Close: 1, Volume: 21, QuoteVolume: 18.5, WeightedAverage: 21 / 18.5},
+		{Date: 900, Open: 1, High: 1, Low: 1, Close: 1, WeightedAverage: 1},
+		{Date: 1200, Open: 4, High: 4, Low: 4, Close: 4, Volume: 4, QuoteVolume: 1, WeightedAverage: 4},
+	}
+	if got := CandlesFromTrades(trades, Period5m); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+	if trades[0].Date != 1210 {
+		t.Errorf("CandlesFromTrades reordered its argument")
+	}
+}
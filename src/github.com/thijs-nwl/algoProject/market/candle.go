@@ -0,0 +1,51 @@
+// Package market holds the exchange independent types shared by the
+// algoProject tools: candles, currency pairs and candle periods.
+package market
+
+import (
+	"math"
+	"time"
+)
+
+// Candle is a single bar of chart data. The fields mirror the exchange
+// payload: Date is the Unix time the bar opens, Volume is traded in the
+// quote currency and QuoteVolume in the base currency.
+type Candle struct {
+	Date            int64   `json:"date"`
+	High            float64 `json:"high"`
+	Low             float64 `json:"low"`
+	Open            float64 `json:"open"`
+	Close           float64 `json:"close"`
+	Volume          float64 `json:"volume"`
+	QuoteVolume     float64 `json:"quoteVolume"`
+	WeightedAverage float64 `json:"weightedAverage"`
+}
+
+// Time returns Date as a UTC time.
+func (c Candle) Time() time.Time {
+	return time.Unix(c.Date, 0).UTC()
+}
+
+// Change is the move from open to close; it is negative for a falling bar.
+func (c Candle) Change() float64 {
+	return c.Close - c.Open
+}
+
+// PercentChange is Change relative to the open, in percent.
+func (c Candle) PercentChange() float64 {
+	if c.Open == 0 {
+		return 0
+	}
+	return c.Change() / c.Open * 100
+}
+
+// Range is the distance between high and low.
+func (c Candle) Range() float64 {
+	return c.High - c.Low
+}
+
+// Body is the size of the bar between open and close, regardless of
+// direction.
+func (c Candle) Body() float64 {
+	return math.Abs(c.Change())
+}
@@ -0,0 +1,36 @@
+package market
+
+// Iterator yields candles one at a time, in the style of bufio.Scanner:
+// call Next until it returns false, read each candle with Candle, and
+// check Err at the end.
+type Iterator interface {
+	Next() bool
+	Candle() Candle
+	Err() error
+}
+
+// SliceIterator iterates over candles held in memory.
+type SliceIterator struct {
+	candles []Candle
+	i       int
+}
+
+// NewSliceIterator returns an Iterator over candles.
+func NewSliceIterator(candles []Candle) *SliceIterator {
+	return &SliceIterator{candles: candles, i: -1}
+}
+
+// Next advances to the next candle.
+func (it *SliceIterator) Next() bool {
+	if it.i+1 >= len(it.candles) {
+		return false
+	}
+	it.i++
+	return true
+}
+
+// Candle returns the current candle.
+func (it *SliceIterator) Candle() Candle { return it.candles[it.i] }
+
+// Err always returns nil.
+func (it *SliceIterator) Err() error { return nil }
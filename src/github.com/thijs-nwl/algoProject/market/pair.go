@@ -0,0 +1,127 @@
+package market
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pair is a currency pair. The base is the currency being priced and the
+// quote the currency the price is expressed in, so the Poloniex market
+// BTC_XMR has base XMR and quote BTC. Currency codes are kept in upper case
+// under their common names, BTC rather than Kraken's XBT.
+type Pair struct {
+	base  string
+	quote string
+}
+
+// NewPair returns the pair pricing base in quote.
+func NewPair(base, quote string) Pair {
+	return Pair{base: currency(base), quote: currency(quote)}
+}
+
+// ParsePair reads a pair in any of the spellings in use:
+//
+//	BTC_XMR  quote and base separated by an underscore, as on Poloniex and in the datastore
+//	XMR/BTC  base and quote separated by a slash or dash
+//	XMRBTC   base and quote run together, split at a known quote currency
+//
+// Exchange specific currency codes such as XBT are mapped to their common
+// names, so symbols written by Pair.Symbol parse back to the same pair.
+func ParsePair(s string) (Pair, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if parts := strings.Split(s, "_"); len(parts) == 2 {
+		return checkPair(NewPair(parts[1], parts[0]), s)
+	}
+	for _, sep := range []string{"/", "-"} {
+		if parts := strings.Split(s, sep); len(parts) == 2 {
+			return checkPair(NewPair(parts[0], parts[1]), s)
+		}
+	}
+	for _, q := range quoteCurrencies {
+		if strings.HasSuffix(s, q) && len(s) > len(q) {
+			return checkPair(NewPair(strings.TrimSuffix(s, q), q), s)
+		}
+	}
+	return Pair{}, fmt.Errorf("market: cannot parse pair %q", s)
+}
+
+// MustParsePair is like ParsePair but panics if s cannot be parsed. It is
+// meant for pairs written in the source.
+func MustParsePair(s string) Pair {
+	p, err := ParsePair(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// checkPair returns p if both its codes are letters and digits only and
+// differ, so a leftover separator cannot end up inside a code.
+func checkPair(p Pair, s string) (Pair, error) {
+	if !validCode(p.base) || !validCode(p.quote) || p.base == p.quote {
+		return Pair{}, fmt.Errorf("market: cannot parse pair %q", s)
+	}
+	return p, nil
+}
+
+func validCode(code string) bool {
+	if code == "" {
+		return false
+	}
+	for _, r := range code {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// Base returns the currency being priced.
+func (p Pair) Base() string { return p.base }
+
+// Quote returns the currency the price is expressed in.
+func (p Pair) Quote() string { return p.quote }
+
+// Invert returns the pair with base and quote swapped.
+func (p Pair) Invert() Pair {
+	return Pair{base: p.quote, quote: p.base}
+}
+
+// String returns the pair in the QUOTE_BASE form algoProject uses for
+// flags and datastore file names.
+func (p Pair) String() string {
+	return p.quote + "_" + p.base
+}
+
+// MarshalText implements encoding.TextMarshaler using String.
+func (p Pair) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler using ParsePair.
+func (p *Pair) UnmarshalText(b []byte) error {
+	pair, err := ParsePair(string(b))
+	if err != nil {
+		return err
+	}
+	*p = pair
+	return nil
+}
+
+// quoteCurrencies are tried, longest first, to split run together symbols.
+var quoteCurrencies = []string{"USDT", "USDC", "BUSD", "USD", "EUR", "BTC", "XBT", "ETH", "BNB", "XMR"}
+
+// aliases maps other spellings of a currency code to its common name.
+var aliases = map[string]string{
+	"XBT": "BTC",
+	"XDG": "DOGE",
+	"STR": "XLM",
+}
+
+func currency(s string) string {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if name, ok := aliases[s]; ok {
+		return name
+	}
+	return s
+}
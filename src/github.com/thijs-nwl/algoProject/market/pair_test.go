@@ -0,0 +1,52 @@
+package market
+
+import "testing"
+
+func TestParsePair(t *testing.T) {
+	tests := []struct {
+		in          string
+		base, quote string
+	}{
+		{"BTC_XMR", "XMR", "BTC"},
+		{"btc_xmr", "XMR", "BTC"},
+		{" USDT_ETH ", "ETH", "USDT"},
+		{"XMR/BTC", "XMR", "BTC"},
+		{"XMR-BTC", "XMR", "BTC"},
+		{"XBT/EUR", "BTC", "EUR"},
+		{"XMRBTC", "XMR", "BTC"},
+		{"ETHUSDT", "ETH", "USDT"},
+	}
+	for _, tt := range tests {
+		p, err := ParsePair(tt.in)
+		if err != nil {
+			t.Errorf("ParsePair(%q): %v", tt.in, err)
+			continue
+		}
+		if p.Base() != tt.base || p.Quote() != tt.quote {
+			t.Errorf("ParsePair(%q) = base %q, quote %q, want base %q, quote %q", tt.in, p.Base(), p.Quote(), tt.base, tt.quote)
+		}
+	}
+}
+
+func TestParsePairInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"BTC",
+		"BTC_",
+		"_XMR",
+		"BTC_BTC",
+		"BTC_XMR_ETH",
+		"XMR/BTC/ETH",
+		"XMR-BTC/ETH",
+		"XMR_BTC-ETH",
+		"XMR/BT C",
+		"XMR$/BTC",
+		"BTC_XMR!",
+		"/BTC",
+		"BTCBTC",
+	} {
+		if p, err := ParsePair(in); err == nil {
+			t.Errorf("ParsePair(%q) = %v, want an error", in, p)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package market
+
+import (
+	"fmt"
+	"time"
+)
+
+// Period is the length of a candle in seconds.
+type Period int
+
+// The candle periods offered by the exchange.
+const (
+	Period5m  Period = 300
+	Period15m Period = 900
+	Period30m Period = 1800
+	Period2h  Period = 7200
+	Period4h  Period = 14400
+	Period1d  Period = 86400
+)
+
+// Periods that Poloniex does not serve but Resample can build.
+const (
+	Period1h Period = 3600
+	Period1w Period = 7 * 86400
+)
+
+// Periods lists every supported candle period, shortest first.
+var Periods = []Period{Period5m, Period15m, Period30m, Period2h, Period4h, Period1d}
+
+// Duration returns the period as a time.Duration.
+func (p Period) Duration() time.Duration {
+	return time.Duration(p) * time.Second
+}
+
+// Valid reports whether p is one of the candle periods offered by the
+// exchange.
+func (p Period) Valid() bool {
+	for _, v := range Periods {
+		if p == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Period) String() string {
+	return fmt.Sprintf("%d", int(p))
+}
@@ -0,0 +1,84 @@
+package market
+
+import "fmt"
+
+// EdgeMode selects what Resample does with incomplete bars at the start
+// and end of a series.
+type EdgeMode int
+
+const (
+	// KeepPartial returns edge bars built from fewer candles than a full
+	// bar holds.
+	KeepPartial EdgeMode = iota
+	// DropPartial leaves incomplete edge bars out.
+	DropPartial
+)
+
+// Resample aggregates candles of period base into bars of period target,
+// which must be a multiple of base. Bars are aligned to multiples of target
+// since the Unix epoch, so daily bars start at midnight UTC and hourly bars
+// on the hour; weekly bars start on Thursday, as the epoch did.
+//
+// Each bar opens at its first candle's open, closes at its last candle's
+// close, spans the extremes of their highs and lows, and sums their
+// volumes. The weighted average is recomputed from the summed volumes.
+func Resample(candles []Candle, base, target Period, edges EdgeMode) ([]Candle, error) {
+	if base <= 0 || target < base || target%base != 0 {
+		return nil, fmt.Errorf("market: cannot resample %v second candles to %v seconds", base, target)
+	}
+
+	var bars []Candle
+	var counts []int
+	for _, c := range Merge(candles) {
+		start := c.Date - mod(c.Date, int64(target))
+		if n := len(bars); n > 0 && bars[n-1].Date == start {
+			addCandle(&bars[n-1], c)
+			counts[n-1]++
+			continue
+		}
+
+		bar := c
+		bar.Date = start
+		bars = append(bars, bar)
+		counts = append(counts, 1)
+	}
+
+	for i := range bars {
+		if bars[i].QuoteVolume > 0 {
+			bars[i].WeightedAverage = bars[i].Volume / bars[i].QuoteVolume
+		}
+	}
+
+	if edges == DropPartial && len(bars) > 0 {
+		full := int(target / base)
+		if counts[len(bars)-1] < full {
+			bars = bars[:len(bars)-1]
+		}
+		if len(bars) > 0 && counts[0] < full {
+			bars = bars[1:]
+		}
+	}
+	return bars, nil
+}
+
+func addCandle(bar *Candle, c Candle) {
+	if c.High > bar.High {
+		bar.High = c.High
+	}
+	if c.Low < bar.Low {
+		bar.Low = c.Low
+	}
+	bar.Close = c.Close
+	bar.Volume += c.Volume
+	bar.QuoteVolume += c.QuoteVolume
+}
+
+// mod is the remainder of a/b with the sign of b, so dates before the
+// epoch still align downwards.
+func mod(a, b int64) int64 {
+	m := a % b
+	if m < 0 {
+		m += b
+	}
+	return m
+}
@@ -0,0 +1,28 @@
+package market
+
+import (
+	"errors"
+	"sort"
+)
+
+// ErrNoData is returned by candle sources when a range holds no candles.
+var ErrNoData = errors.New("market: no candles in range")
+
+// Merge combines several candle series into one series ordered by date.
+// When more than one series holds a candle for the same date, the one from
+// the latest series wins.
+func Merge(series ...[]Candle) []Candle {
+	byDate := make(map[int64]Candle)
+	for _, s := range series {
+		for _, c := range s {
+			byDate[c.Date] = c
+		}
+	}
+
+	merged := make([]Candle, 0, len(byDate))
+	for _, c := range byDate {
+		merged = append(merged, c)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Date < merged[j].Date })
+	return merged
+}
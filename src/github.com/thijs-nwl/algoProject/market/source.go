@@ -0,0 +1,13 @@
+package market
+
+import (
+	"context"
+	"time"
+)
+
+// CandleSource is a provider of candle data, such as an exchange API or a
+// file. Implementations return the candles of pair at period between from
+// and to inclusive, ordered by date, or ErrNoData when there are none.
+type CandleSource interface {
+	FetchCandles(ctx context.Context, pair Pair, period Period, from, to time.Time) ([]Candle, error)
+}
@@ -0,0 +1,36 @@
+package market
+
+// Exchange names a market whose symbols Pair can be spelled in.
+type Exchange string
+
+// The exchanges algoProject has sources for.
+const (
+	Poloniex Exchange = "poloniex"
+	Binance  Exchange = "binance"
+	Kraken   Exchange = "kraken"
+)
+
+// exchangeNames maps currency codes to the names an exchange uses instead.
+var exchangeNames = map[Exchange]map[string]string{
+	Poloniex: {"XLM": "STR"},
+	Kraken:   {"BTC": "XBT", "DOGE": "XDG"},
+}
+
+// Symbol spells p the way ex does: QUOTE_BASE on Poloniex and BASEQUOTE on
+// Binance and Kraken, with the exchange's own currency codes. Unknown
+// exchanges get the String form.
+func (p Pair) Symbol(ex Exchange) string {
+	base, quote := exchangeName(ex, p.base), exchangeName(ex, p.quote)
+	switch ex {
+	case Binance, Kraken:
+		return base + quote
+	}
+	return quote + "_" + base
+}
+
+func exchangeName(ex Exchange, code string) string {
+	if name, ok := exchangeNames[ex][code]; ok {
+		return name
+	}
+	return code
+}
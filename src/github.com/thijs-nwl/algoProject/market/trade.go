@@ -0,0 +1,138 @@
+package market
+
+import (
+	"sort"
+	"time"
+)
+
+// Side is the direction of an order, or the side that took liquidity in a
+// trade.
+type Side string
+
+// The two sides.
+const (
+	Buy  Side = "buy"
+	Sell Side = "sell"
+)
+
+// Trade is a single executed trade. Amount is in the base currency and
+// Total in the quote currency.
+type Trade struct {
+	ID     int64   `json:"id"`
+	Date   int64   `json:"date"`
+	Side   Side    `json:"side"`
+	Price  float64 `json:"price"`
+	Amount float64 `json:"amount"`
+	Total  float64 `json:"total"`
+}
+
+// Time returns Date as a UTC time.
+func (t Trade) Time() time.Time {
+	return time.Unix(t.Date, 0).UTC()
+}
+
+// Level is the amount of the base currency offered at one price.
+type Level struct {
+	Price  float64 `json:"price"`
+	Amount float64 `json:"amount"`
+}
+
+// OrderBook is a snapshot of the resting orders of a market. Asks are
+// ordered from the lowest price up and bids from the highest price down.
+type OrderBook struct {
+	Date int64   `json:"date"`
+	Seq  int64   `json:"seq"`
+	Asks []Level `json:"asks"`
+	Bids []Level `json:"bids"`
+}
+
+// Mid returns the price halfway between the best ask and the best bid.
+func (b OrderBook) Mid() float64 {
+	if len(b.Asks) == 0 || len(b.Bids) == 0 {
+		return 0
+	}
+	return (b.Asks[0].Price + b.Bids[0].Price) / 2
+}
+
+// FillPrice returns the average price a market order for amount would get
+// by walking the book: a buy takes the asks and a sell the bids. It
+// reports false when the book is not deep enough.
+func (b OrderBook) FillPrice(side Side, amount float64) (float64, bool) {
+	levels := b.Asks
+	if side == Sell {
+		levels = b.Bids
+	}
+
+	var filled, cost float64
+	for _, l := range levels {
+		take := l.Amount
+		if left := amount - filled; take > left {
+			take = left
+		}
+		filled += take
+		cost += take * l.Price
+		if filled >= amount {
+			return cost / filled, amount > 0
+		}
+	}
+	return 0, false
+}
+
+// Slippage returns how far the fill price of a market order for amount is
+// from the mid price, as a fraction. It can be used as the slippage of a
+// backtest. It reports false when the book is not deep enough.
+func (b OrderBook) Slippage(side Side, amount float64) (float64, bool) {
+	price, ok := b.FillPrice(side, amount)
+	mid := b.Mid()
+	if !ok || mid == 0 {
+		return 0, false
+	}
+	if side == Sell {
+		return 1 - price/mid, true
+	}
+	return price/mid - 1, true
+}
+
+// MergeTrades combines several trade lists into one ordered by date and
+// ID, keeping one copy of trades that appear more than once. Trades with
+// an ID are the same when their IDs are; trades without one, ID 0, when
+// their date, price, amount and side all are. Of two copies the later one
+// is kept.
+func MergeTrades(lists ...[]Trade) []Trade {
+	index := make(map[tradeKey]int)
+	var merged []Trade
+	for _, l := range lists {
+		for _, t := range l {
+			k := keyOf(t)
+			if i, ok := index[k]; ok {
+				merged[i] = t
+				continue
+			}
+			index[k] = len(merged)
+			merged = append(merged, t)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Date != merged[j].Date {
+			return merged[i].Date < merged[j].Date
+		}
+		return merged[i].ID < merged[j].ID
+	})
+	return merged
+}
+
+// tradeKey identifies a trade for MergeTrades.
+type tradeKey struct {
+	id            int64
+	date          int64
+	price, amount float64
+	side          Side
+}
+
+func keyOf(t Trade) tradeKey {
+	if t.ID != 0 {
+		return tradeKey{id: t.ID}
+	}
+	return tradeKey{date: t.Date, price: t.Price, amount: t.Amount, side: t.Side}
+}
@@ -0,0 +1,50 @@
+package market
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeTrades(t *testing.T) {
+	tests := []struct {
+		name  string
+		lists [][]Trade
+		want  []Trade
+	}{
+		{
+			name: "duplicate IDs",
+			lists: [][]Trade{
+				{{ID: 2, Date: 702, Price: 2, Amount: 1}, {ID: 1, Date: 701, Price: 1, Amount: 1}},
+				{{ID: 2, Date: 702, Price: 3, Amount: 1}},
+			},
+			want: []Trade{{ID: 1, Date: 701, Price: 1, Amount: 1}, {ID: 2, Date: 702, Price: 3, Amount: 1}},
+		},
+		{
+			name: "distinct trades without ID",
+			lists: [][]Trade{
+				{{Date: 702, Price: 2, Amount: 8, Side: Buy}, {Date: 701, Price: 1, Amount: 5, Side: Buy}},
+			},
+			want: []Trade{{Date: 701, Price: 1, Amount: 5, Side: Buy}, {Date: 702, Price: 2, Amount: 8, Side: Buy}},
+		},
+		{
+			name: "same second without ID",
+			lists: [][]Trade{
+				{{Date: 701, Price: 1, Amount: 5, Side: Buy}, {Date: 701, Price: 1, Amount: 5, Side: Sell}, {Date: 701, Price: 1, Amount: 6, Side: Buy}},
+			},
+			want: []Trade{{Date: 701, Price: 1, Amount: 5, Side: Buy}, {Date: 701, Price: 1, Amount: 5, Side: Sell}, {Date: 701, Price: 1, Amount: 6, Side: Buy}},
+		},
+		{
+			name: "repeated trade without ID",
+			lists: [][]Trade{
+				{{Date: 701, Price: 1, Amount: 5, Side: Buy}},
+				{{Date: 701, Price: 1, Amount: 5, Side: Buy}},
+			},
+			want: []Trade{{Date: 701, Price: 1, Amount: 5, Side: Buy}},
+		},
+	}
+	for _, tt := range tests {
+		if got := MergeTrades(tt.lists...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package market
+
+import (
+	"fmt"
+	"math"
+)
+
+// IssueKind classifies a problem found in a candle series.
+type IssueKind int
+
+// The problems Validate reports.
+const (
+	Gap IssueKind = iota
+	Duplicate
+	OutOfOrder
+	Misaligned
+	Malformed
+)
+
+func (k IssueKind) String() string {
+	switch k {
+	case Gap:
+		return "gap"
+	case Duplicate:
+		return "duplicate"
+	case OutOfOrder:
+		return "out of order"
+	case Misaligned:
+		return "misaligned"
+	case Malformed:
+		return "malformed"
+	}
+	return fmt.Sprintf("IssueKind(%d)", int(k))
+}
+
+// Issue is a single problem in a candle series. Date is the time of the
+// offending candle; for a gap it is the first missing candle time.
+type Issue struct {
+	Kind   IssueKind
+	Date   int64
+	Detail string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%v %v: %v", Candle{Date: i.Date}.Time().Format("2006-01-02 15:04:05"), i.Kind, i.Detail)
+}
+
+// Validate checks that candles are sorted, evenly spaced at period, free
+// of duplicates and internally consistent, and returns every problem it
+// finds in series order. It returns an error for a period that is not
+// positive.
+func Validate(candles []Candle, period Period) ([]Issue, error) {
+	v, err := NewValidator(period)
+	if err != nil {
+		return nil, err
+	}
+	var issues []Issue
+	for _, c := range candles {
+		issues = append(issues, v.Check(c)...)
+	}
+	return issues, nil
+}
+
+// checkPeriod returns an error for a period candles cannot be spaced at.
+func checkPeriod(period Period) error {
+	if period <= 0 {
+		return fmt.Errorf("market: invalid period %d", int(period))
+	}
+	return nil
+}
+
+// Validator runs the checks of Validate on a stream of candles, one at a
+// time.
+type Validator struct {
+	step    int64
+	prev    int64
+	started bool
+}
+
+// NewValidator returns a Validator for candles of period, or an error
+// for a period that is not positive.
+func NewValidator(period Period) (*Validator, error) {
+	if err := checkPeriod(period); err != nil {
+		return nil, err
+	}
+	return &Validator{step: int64(period)}, nil
+}
+
+// Check returns the problems with c, given the candles checked before it.
+func (v *Validator) Check(c Candle) []Issue {
+	var issues []Issue
+	if msg := checkCandle(c); msg != "" {
+		issues = append(issues, Issue{Malformed, c.Date, msg})
+	}
+	if c.Date%v.step != 0 {
+		issues = append(issues, Issue{Misaligned, c.Date, fmt.Sprintf("not a multiple of %v seconds", v.step)})
+	}
+
+	prev, started := v.prev, v.started
+	v.prev, v.started = c.Date, true
+	if !started {
+		return issues
+	}
+
+	switch {
+	case c.Date < prev:
+		issues = append(issues, Issue{OutOfOrder, c.Date, fmt.Sprintf("follows %v", prev)})
+	case c.Date == prev:
+		issues = append(issues, Issue{Duplicate, c.Date, "date seen twice"})
+	case c.Date-prev > v.step:
+		missing := (c.Date - prev - 1) / v.step
+		issues = append(issues, Issue{Gap, prev + v.step, fmt.Sprintf("%d candles missing", missing)})
+	}
+	return issues
+}
+
+// checkCandle returns why c is not a sane candle, or "" if it is.
+func checkCandle(c Candle) string {
+	switch {
+	case c.Open <= 0 || c.Close <= 0 || c.High <= 0 || c.Low <= 0:
+		return "non-positive price"
+	case c.High < math.Max(c.Open, c.Close):
+		return "high below open or close"
+	case c.Low > math.Min(c.Open, c.Close):
+		return "low above open or close"
+	case c.Volume < 0 || c.QuoteVolume < 0:
+		return "negative volume"
+	}
+	return ""
+}
+
+// RepairMode selects how Repair deals with missing candles.
+type RepairMode int
+
+// The ways Repair can treat a gap.
+const (
+	// Drop leaves gaps in place.
+	Drop RepairMode = iota
+	// ForwardFill fills a gap with flat candles at the last close.
+	ForwardFill
+	// Interpolate fills a gap with flat candles on a straight line from the
+	// last close to the next open.
+	Interpolate
+)
+
+// Repair returns a sorted copy of candles without duplicates, misaligned
+// or malformed candles, with gaps treated according to mode. Filled
+// candles carry no volume. It returns an error for a period that is not
+// positive.
+func Repair(candles []Candle, period Period, mode RepairMode) ([]Candle, error) {
+	if err := checkPeriod(period); err != nil {
+		return nil, err
+	}
+	step := int64(period)
+
+	var clean []Candle
+	for _, c := range Merge(candles) {
+		if c.Date%step == 0 && checkCandle(c) == "" {
+			clean = append(clean, c)
+		}
+	}
+	if mode == Drop {
+		return clean, nil
+	}
+
+	var repaired []Candle
+	for i, c := range clean {
+		if i > 0 {
+			prev := clean[i-1]
+			n := (c.Date - prev.Date) / step
+			for k := int64(1); k < n; k++ {
+				price := prev.Close
+				if mode == Interpolate {
+					price += (c.Open - prev.Close) * float64(k) / float64(n)
+				}
+				repaired = append(repaired, flat(prev.Date+k*step, price))
+			}
+		}
+		repaired = append(repaired, c)
+	}
+	return repaired, nil
+}
+
+func flat(date int64, price float64) Candle {
+	return Candle{Date: date, High: price, Low: price, Open: price, Close: price, WeightedAverage: price}
+}
@@ -0,0 +1,18 @@
+package market
+
+import "testing"
+
+func TestBadPeriod(t *testing.T) {
+	candles := []Candle{{Date: 300, Open: 1, High: 1, Low: 1, Close: 1}}
+	for _, period := range []Period{0, -300} {
+		if _, err := NewValidator(period); err == nil {
+			t.Errorf("NewValidator(%v) succeeded, want an error", period)
+		}
+		if _, err := Validate(candles, period); err == nil {
+			t.Errorf("Validate with period %v succeeded, want an error", period)
+		}
+		if _, err := Repair(candles, period, ForwardFill); err == nil {
+			t.Errorf("Repair with period %v succeeded, want an error", period)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// Package paper runs a trading strategy forward on live candles without
+// real money. It uses the same Strategy and fill simulation as the
+// backtester, and keeps its portfolio on disk so a restarted trader picks
+// up where it left off.
+package paper
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/backtest"
+	"github.com/thijs-nwl/algoProject/datastore"
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// State is what a Trader persists between runs.
+type State struct {
+	Portfolio backtest.Portfolio `json:"portfolio"`
+	Trades    []backtest.Trade   `json:"trades"`
+	Pending   []backtest.Order   `json:"pending"`
+	// Last is the date of the last candle given to the strategy.
+	Last int64 `json:"last"`
+}
+
+// Trader polls Source for closed candles of Pair and feeds them to
+// Strategy. Orders are filled at the open of the next candle, as in a
+// backtest.
+type Trader struct {
+	Source   market.CandleSource
+	Pair     market.Pair
+	Period   market.Period
+	Strategy backtest.Strategy
+	Config   backtest.Config
+
+	// Store, when set, keeps the candles the trader sees. Warmup then
+	// only fetches the candles that are not stored yet.
+	Store datastore.Backend
+
+	// StatePath is the file the state is kept in.
+	StatePath string
+	// Warmup is the number of past candles fed to the strategy, with its
+	// orders ignored, before trading starts or resumes.
+	Warmup int
+	// Delay is how long to wait after a period boundary before asking for
+	// the candle that just closed, to give the exchange time to publish it.
+	Delay time.Duration
+	Log   *log.Logger
+}
+
+// Run trades until ctx is done. It returns ctx.Err() or the first error
+// loading or saving state; failed polls are logged and retried at the next
+// boundary.
+func (t *Trader) Run(ctx context.Context) error {
+	state, err := t.load()
+	if err != nil {
+		return err
+	}
+	acct := backtest.NewAccount(t.Config)
+	acct.Portfolio, acct.Trades = state.Portfolio, state.Trades
+
+	step := int64(t.Period)
+	if state.Last == 0 {
+		state.Last = lastClosed(time.Now(), step)
+	}
+	t.warmup(ctx, state.Last)
+	if err := t.save(state); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(t.untilNextPoll(time.Now())):
+		}
+
+		candles, err := t.poll(ctx, state.Last)
+		if err != nil {
+			t.logf("polling %v: %v", t.Pair, err)
+			continue
+		}
+		for _, c := range candles {
+			for _, o := range state.Pending {
+				if tr, ok := acct.Fill(o, c); ok {
+					t.logf("%v", tr)
+				}
+			}
+			state.Pending = t.Strategy.OnCandle(c, acct.Portfolio)
+			state.Last = c.Date
+		}
+		state.Portfolio, state.Trades = acct.Portfolio, acct.Trades
+
+		if len(candles) > 0 {
+			last := candles[len(candles)-1]
+			t.logf("%v close %v, equity %.8f", last.Time().Format("2006-01-02 15:04"), last.Close, acct.Portfolio.Equity(last.Close))
+		}
+		if err := t.save(state); err != nil {
+			return err
+		}
+	}
+}
+
+// untilNextPoll returns the wait until Delay past the next period
+// boundary.
+func (t *Trader) untilNextPoll(now time.Time) time.Duration {
+	step := int64(t.Period)
+	at := time.Unix(now.Unix()/step*step, 0).Add(t.Delay)
+	if !at.After(now) {
+		at = at.Add(t.Period.Duration())
+	}
+	return at.Sub(now)
+}
+
+// poll returns the closed candles after last.
+func (t *Trader) poll(ctx context.Context, last int64) ([]market.Candle, error) {
+	step := int64(t.Period)
+	to := lastClosed(time.Now(), step)
+	if to <= last {
+		return nil, nil
+	}
+
+	candles, err := t.Source.FetchCandles(ctx, t.Pair, t.Period, time.Unix(last+step, 0), time.Unix(to, 0))
+	if err == market.ErrNoData {
+		return nil, nil
+	}
+	if err == nil && t.Store != nil {
+		err = t.Store.Save(t.Pair, t.Period, candles)
+	}
+	return candles, err
+}
+
+// warmup replays the candles up to and including last so the strategy's
+// indicators are primed.
+func (t *Trader) warmup(ctx context.Context, last int64) {
+	if t.Warmup <= 0 {
+		return
+	}
+	from, to := time.Unix(last-int64(t.Warmup-1)*int64(t.Period), 0), time.Unix(last, 0)
+	candles, err := t.warmupCandles(ctx, from, to)
+	if err != nil {
+		t.logf("warming up %v: %v", t.Pair, err)
+		return
+	}
+	for _, c := range candles {
+		t.Strategy.OnCandle(c, backtest.Portfolio{})
+	}
+	t.logf("warmed up on %d candles", len(candles))
+}
+
+// warmupCandles returns the candles between from and to, from Store when
+// there is one.
+func (t *Trader) warmupCandles(ctx context.Context, from, to time.Time) ([]market.Candle, error) {
+	if t.Store == nil {
+		return t.Source.FetchCandles(ctx, t.Pair, t.Period, from, to)
+	}
+	if _, err := datastore.Update(ctx, t.Store, t.Source, t.Pair, t.Period, from, to); err != nil {
+		return nil, err
+	}
+	return t.Store.Load(t.Pair, t.Period, from, to)
+}
+
+// load reads the saved state, or starts from the configured cash.
+func (t *Trader) load() (*State, error) {
+	b, err := ioutil.ReadFile(t.StatePath)
+	if os.IsNotExist(err) {
+		return &State{Portfolio: backtest.Portfolio{Cash: t.Config.Cash}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s State
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+	t.logf("resuming after %v with %+v", time.Unix(s.Last, 0).UTC(), s.Portfolio)
+	return &s, nil
+}
+
+// save writes the state to a temporary file and renames it into place.
+func (t *Trader) save(s *State) error {
+	b, err := json.MarshalIndent(s, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	tmp := t.StatePath + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, t.StatePath)
+}
+
+func (t *Trader) logf(format string, args ...interface{}) {
+	if t.Log != nil {
+		t.Log.Printf(format, args...)
+	}
+}
+
+// lastClosed returns the open time of the latest candle that has closed
+// by now.
+func lastClosed(now time.Time, step int64) int64 {
+	return now.Unix()/step*step - step
+}
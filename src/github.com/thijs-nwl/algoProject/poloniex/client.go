@@ -0,0 +1,167 @@
+// Package poloniex is a client for the public Poloniex market data API.
+package poloniex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+	"github.com/thijs-nwl/algoProject/request"
+)
+
+// DefaultBaseURL is the public API endpoint of Poloniex.
+const DefaultBaseURL = "https://poloniex.com/public"
+
+// DefaultWindow is the number of candles asked for in a single request.
+const DefaultWindow = 5000
+
+// Client fetches market data from the Poloniex public API.
+type Client struct {
+	BaseURL  string
+	Requests *request.Client
+
+	// Window is the maximum number of candles per request; longer ranges
+	// are split into several requests. DefaultWindow is used when zero.
+	Window int
+	// Concurrency bounds the number of requests in flight for one range.
+	// Requests are made one after another when zero.
+	Concurrency int
+}
+
+// NewClient returns a Client for the live Poloniex API.
+func NewClient() *Client {
+	return &Client{
+		BaseURL:  DefaultBaseURL,
+		Requests: request.New(),
+	}
+}
+
+// ChartURL returns the returnChartData request for the given range.
+func (c *Client) ChartURL(pair market.Pair, start, end time.Time, period market.Period) string {
+	q := url.Values{}
+	q.Set("command", "returnChartData")
+	q.Set("currencyPair", pair.Symbol(market.Poloniex))
+	q.Set("start", strconv.FormatInt(start.Unix(), 10))
+	q.Set("end", strconv.FormatInt(end.Unix(), 10))
+	q.Set("period", period.String())
+	return c.BaseURL + "?" + q.Encode()
+}
+
+// ChartURLs returns the requests ChartData makes for the given range, one
+// per window.
+func (c *Client) ChartURLs(pair market.Pair, start, end time.Time, period market.Period) []string {
+	var urls []string
+	for _, w := range split(start, end, period, c.window()) {
+		urls = append(urls, c.ChartURL(pair, w.start, w.end, period))
+	}
+	return urls
+}
+
+// ChartData returns the candles of pair between start and end. Ranges
+// longer than Window candles are fetched in several requests and stitched
+// into one ordered series without duplicates. Cancelling ctx abandons the
+// requests still in flight.
+func (c *Client) ChartData(ctx context.Context, pair market.Pair, start, end time.Time, period market.Period) ([]market.Candle, error) {
+	if !period.Valid() {
+		return nil, fmt.Errorf("poloniex: unsupported period %v", period)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("poloniex: end %v before start %v", end, start)
+	}
+
+	windows := split(start, end, period, c.window())
+	results := make([][]market.Candle, len(windows))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, c.concurrency())
+	for i, w := range windows {
+		sem <- struct{}{}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, w window) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			candles, err := c.chartWindow(ctx, pair, w.start, w.end, period)
+			if err != nil && err != market.ErrNoData {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+			results[i] = candles
+		}(i, w)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	candles := market.Merge(results...)
+	if len(candles) == 0 {
+		return nil, market.ErrNoData
+	}
+	return candles, nil
+}
+
+func (c *Client) chartWindow(ctx context.Context, pair market.Pair, start, end time.Time, period market.Period) ([]market.Candle, error) {
+	body, err := c.Requests.Get(ctx, c.ChartURL(pair, start, end, period))
+	if err != nil {
+		return nil, err
+	}
+	return decodeChart(body)
+}
+
+// decodeChart turns a returnChartData body into candles. Anything but a
+// non-empty candle array is reported as an error; the single all-zero
+// candle the exchange sends for a range without data is market.ErrNoData.
+func decodeChart(body []byte) ([]market.Candle, error) {
+	var apiErr APIError
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+		return nil, &apiErr
+	}
+
+	var candles []market.Candle
+	if err := json.Unmarshal(body, &candles); err != nil {
+		return nil, fmt.Errorf("poloniex: decoding chart data: %v", err)
+	}
+	if len(candles) == 0 || len(candles) == 1 && candles[0] == (market.Candle{}) {
+		return nil, market.ErrNoData
+	}
+	return candles, nil
+}
+
+func (c *Client) window() int {
+	if c.Window <= 0 {
+		return DefaultWindow
+	}
+	return c.Window
+}
+
+func (c *Client) concurrency() int {
+	if c.Concurrency <= 0 {
+		return 1
+	}
+	return c.Concurrency
+}
+
+// FetchCandles implements market.CandleSource.
+func (c *Client) FetchCandles(ctx context.Context, pair market.Pair, period market.Period, from, to time.Time) ([]market.Candle, error) {
+	return c.ChartData(ctx, pair, from, to, period)
+}
@@ -0,0 +1,12 @@
+package poloniex
+
+// APIError is the {"error": "..."} object the exchange sends, with status
+// 200, for requests it rejects. Transport and HTTP status failures are
+// reported as *request.TransportError and *request.HTTPError.
+type APIError struct {
+	Message string `json:"error"`
+}
+
+func (e *APIError) Error() string {
+	return "poloniex: " + e.Message
+}
@@ -0,0 +1,165 @@
+package poloniex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// maxTrades is the most trades returnTradeHistory answers with.
+const maxTrades = 1000
+
+// OrderBook returns a snapshot of the depth best bids and asks of pair.
+func (c *Client) OrderBook(ctx context.Context, pair market.Pair, depth int) (*market.OrderBook, error) {
+	q := url.Values{}
+	q.Set("command", "returnOrderBook")
+	q.Set("currencyPair", pair.Symbol(market.Poloniex))
+	q.Set("depth", strconv.Itoa(depth))
+
+	body, err := c.get(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw struct {
+		Asks [][2]json.Number `json:"asks"`
+		Bids [][2]json.Number `json:"bids"`
+		Seq  int64            `json:"seq"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("poloniex: decoding order book: %v", err)
+	}
+
+	book := &market.OrderBook{Date: time.Now().Unix(), Seq: raw.Seq}
+	if book.Asks, err = levels(raw.Asks); err != nil {
+		return nil, err
+	}
+	if book.Bids, err = levels(raw.Bids); err != nil {
+		return nil, err
+	}
+	return book, nil
+}
+
+func levels(raw [][2]json.Number) ([]market.Level, error) {
+	out := make([]market.Level, len(raw))
+	for i, l := range raw {
+		price, err := l[0].Float64()
+		if err != nil {
+			return nil, fmt.Errorf("poloniex: invalid order book price %q", l[0])
+		}
+		amount, err := l[1].Float64()
+		if err != nil {
+			return nil, fmt.Errorf("poloniex: invalid order book amount %q", l[1])
+		}
+		out[i] = market.Level{Price: price, Amount: amount}
+	}
+	return out, nil
+}
+
+type rawTrade struct {
+	ID     int64  `json:"globalTradeID"`
+	Date   string `json:"date"`
+	Type   string `json:"type"`
+	Rate   string `json:"rate"`
+	Amount string `json:"amount"`
+	Total  string `json:"total"`
+}
+
+// TradeHistory returns the trades of pair between start and end, oldest
+// first. Ranges with more trades than one response holds are fetched page
+// by page, walking back from end. The API selects trades by whole seconds,
+// so each page asks again for the second the previous one stopped in.
+// When a single second holds a full page there is no way to reach the
+// rest of it, and TradeHistory returns an error rather than a history
+// with trades missing.
+func (c *Client) TradeHistory(ctx context.Context, pair market.Pair, start, end time.Time) ([]market.Trade, error) {
+	var pages [][]market.Trade
+	for to := end.Unix(); to >= start.Unix(); {
+		page, err := c.tradePage(ctx, pair, start.Unix(), to)
+		if err != nil {
+			return nil, err
+		}
+
+		pages = append(pages, page)
+		oldest := to
+		for _, t := range page {
+			if t.Date < oldest {
+				oldest = t.Date
+			}
+		}
+		if len(page) < maxTrades {
+			break
+		}
+		if oldest == to {
+			return nil, fmt.Errorf("poloniex: more than %d %v trades at %v, which the trade history cannot page through", maxTrades, pair, time.Unix(to, 0).UTC())
+		}
+		to = oldest
+	}
+
+	return market.MergeTrades(pages...), nil
+}
+
+func (c *Client) tradePage(ctx context.Context, pair market.Pair, start, end int64) ([]market.Trade, error) {
+	q := url.Values{}
+	q.Set("command", "returnTradeHistory")
+	q.Set("currencyPair", pair.Symbol(market.Poloniex))
+	q.Set("start", strconv.FormatInt(start, 10))
+	q.Set("end", strconv.FormatInt(end, 10))
+
+	body, err := c.get(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []rawTrade
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("poloniex: decoding trade history: %v", err)
+	}
+
+	trades := make([]market.Trade, 0, len(raw))
+	for _, r := range raw {
+		t, err := r.trade()
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, t)
+	}
+	return trades, nil
+}
+
+func (r rawTrade) trade() (market.Trade, error) {
+	date, err := time.Parse("2006-01-02 15:04:05", r.Date)
+	if err != nil {
+		return market.Trade{}, fmt.Errorf("poloniex: invalid trade date %q", r.Date)
+	}
+	t := market.Trade{ID: r.ID, Date: date.Unix(), Side: market.Side(r.Type)}
+	for _, f := range []struct {
+		s   string
+		dst *float64
+	}{{r.Rate, &t.Price}, {r.Amount, &t.Amount}, {r.Total, &t.Total}} {
+		if *f.dst, err = strconv.ParseFloat(f.s, 64); err != nil {
+			return market.Trade{}, fmt.Errorf("poloniex: invalid trade field %q", f.s)
+		}
+	}
+	return t, nil
+}
+
+// get requests a public API command and returns the body, turning an error
+// object into an *APIError.
+func (c *Client) get(ctx context.Context, q url.Values) ([]byte, error) {
+	body, err := c.Requests.Get(ctx, c.BaseURL+"?"+q.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var apiErr APIError
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+		return nil, &apiErr
+	}
+	return body, nil
+}
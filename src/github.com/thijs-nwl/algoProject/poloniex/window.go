@@ -0,0 +1,29 @@
+package poloniex
+
+import (
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+type window struct {
+	start, end time.Time
+}
+
+// split cuts [start, end] into consecutive windows of at most n candles.
+// The bounds are inclusive, so each window starts one period after the
+// previous one ends.
+func split(start, end time.Time, period market.Period, n int) []window {
+	step := time.Duration(n-1) * period.Duration()
+
+	var windows []window
+	for s := start; !s.After(end); {
+		e := s.Add(step)
+		if e.After(end) {
+			e = end
+		}
+		windows = append(windows, window{s, e})
+		s = e.Add(period.Duration())
+	}
+	return windows
+}
@@ -0,0 +1,41 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// TransportError is a request that did not get a complete HTTP response.
+type TransportError struct {
+	URL string
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("requesting %v: %v", e.URL, e.Err)
+}
+
+func (e *TransportError) Unwrap() error { return e.Err }
+
+// HTTPError is a response with a status code outside 2xx.
+type HTTPError struct {
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%v returned %d: %v", e.URL, e.StatusCode, e.Body)
+}
+
+// Temporary reports whether err is worth retrying: a network failure or
+// timeout, a 429 Too Many Requests or a server side 5xx status.
+func Temporary(err error) bool {
+	switch err := err.(type) {
+	case *TransportError:
+		return true
+	case *HTTPError:
+		return err.StatusCode == http.StatusTooManyRequests || err.StatusCode >= 500
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+// Package request is the HTTP layer used by the exchange clients. It
+// throttles requests to a fixed rate and retries transient failures with
+// exponential backoff.
+package request
+
+import (
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Client performs rate limited GET requests with retries. The zero value
+// makes a single attempt without throttling; use New for sensible
+// defaults. A Client is safe for concurrent use.
+type Client struct {
+	HTTP *http.Client
+
+	// RequestsPerSecond caps the rate at which requests are started,
+	// retries included. Zero means no limit.
+	RequestsPerSecond float64
+	// MaxRetries is the number of extra attempts after a failure on a
+	// timeout, a network error, status 429 or a 5xx status.
+	MaxRetries int
+	// BaseDelay is the wait before the first retry; it doubles with every
+	// attempt up to MaxDelay and is jittered. A longer Retry-After from
+	// the server is honoured, but also only up to MaxDelay. Zero MaxDelay
+	// means no cap.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// New returns a Client with the limits of the Poloniex public API.
+func New() *Client {
+	return &Client{
+		HTTP:              &http.Client{Timeout: 30 * time.Second},
+		RequestsPerSecond: 6,
+		MaxRetries:        5,
+		BaseDelay:         500 * time.Millisecond,
+		MaxDelay:          30 * time.Second,
+	}
+}
+
+// Get fetches url and returns the body of a 2xx response. Failures are
+// reported as *TransportError or *HTTPError, or as the context error when
+// ctx is done.
+func (c *Client) Get(ctx context.Context, url string) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		if err := c.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		body, retryAfter, err := c.get(ctx, url)
+		if err == nil {
+			return body, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if attempt >= c.MaxRetries || !Temporary(err) {
+			return nil, err
+		}
+
+		delay := c.backoff(attempt)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+		if c.MaxDelay > 0 && delay > c.MaxDelay {
+			delay = c.MaxDelay
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) get(ctx context.Context, url string) ([]byte, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, 0, &TransportError{URL: url, Err: err}
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, 0, &TransportError{URL: url, Err: err}
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, retryAfter(res), &HTTPError{URL: url, StatusCode: res.StatusCode, Body: string(body)}
+	}
+	return body, 0, nil
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTP == nil {
+		return http.DefaultClient
+	}
+	return c.HTTP
+}
+
+// wait blocks until the rate limit allows the next request.
+func (c *Client) wait(ctx context.Context) error {
+	if c.RequestsPerSecond <= 0 {
+		return nil
+	}
+	interval := time.Duration(float64(time.Second) / c.RequestsPerSecond)
+
+	c.mu.Lock()
+	now := time.Now()
+	at := c.next
+	if at.Before(now) {
+		at = now
+	}
+	c.next = at.Add(interval)
+	c.mu.Unlock()
+
+	return sleep(ctx, at.Sub(now))
+}
+
+// backoff returns the delay before retry attempt+1: BaseDelay doubled per
+// attempt, capped at MaxDelay, and randomly shortened by up to half.
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.BaseDelay
+	for i := 0; i < attempt && (c.MaxDelay <= 0 || d < c.MaxDelay); i++ {
+		d *= 2
+	}
+	if c.MaxDelay > 0 && d > c.MaxDelay {
+		d = c.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// retryAfter returns the wait a 429 or 503 response asks for.
+func retryAfter(res *http.Response) time.Duration {
+	secs, err := strconv.Atoi(res.Header.Get("Retry-After"))
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,202 @@
+package request
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// server answers the n-th request, counted from 0, with the status and
+// headers respond picks, and records when each request came in.
+type server struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	times []time.Time
+}
+
+func newServer(respond func(n int, w http.ResponseWriter)) *server {
+	s := &server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		n := len(s.times)
+		s.times = append(s.times, time.Now())
+		s.mu.Unlock()
+		respond(n, w)
+	}))
+	return s
+}
+
+func (s *server) requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.times)
+}
+
+// testClient retries quickly so the tests do not wait on the backoff.
+func testClient() *Client {
+	return &Client{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+}
+
+func TestRetryAfter(t *testing.T) {
+	s := newServer(func(n int, w http.ResponseWriter) {
+		if n == 0 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+	defer s.Close()
+
+	c := testClient()
+	c.MaxDelay = 5 * time.Second
+	began := time.Now()
+	body, err := c.Get(context.Background(), s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "ok" {
+		t.Errorf("body = %q, want %q", body, "ok")
+	}
+	if s.requests() != 2 {
+		t.Errorf("made %d requests, want 2", s.requests())
+	}
+	if took := time.Since(began); took < time.Second {
+		t.Errorf("retried after %v, want at least the 1s of Retry-After", took)
+	}
+}
+
+func TestRetryAfterCapped(t *testing.T) {
+	s := newServer(func(n int, w http.ResponseWriter) {
+		if n == 0 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+	defer s.Close()
+
+	began := time.Now()
+	if _, err := testClient().Get(context.Background(), s.URL); err != nil {
+		t.Fatal(err)
+	}
+	if took := time.Since(began); took > 5*time.Second {
+		t.Errorf("retried after %v, want Retry-After capped at MaxDelay", took)
+	}
+}
+
+func TestRetryServerError(t *testing.T) {
+	s := newServer(func(n int, w http.ResponseWriter) {
+		if n < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+	defer s.Close()
+
+	body, err := testClient().Get(context.Background(), s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "ok" {
+		t.Errorf("body = %q, want %q", body, "ok")
+	}
+	if s.requests() != 3 {
+		t.Errorf("made %d requests, want 3", s.requests())
+	}
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	s := newServer(func(n int, w http.ResponseWriter) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer s.Close()
+
+	c := testClient()
+	_, err := c.Get(context.Background(), s.URL)
+	herr, ok := err.(*HTTPError)
+	if !ok || herr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("err = %v, want an *HTTPError with status 500", err)
+	}
+	if want := c.MaxRetries + 1; s.requests() != want {
+		t.Errorf("made %d requests, want %d", s.requests(), want)
+	}
+}
+
+func TestClientErrorNotRetried(t *testing.T) {
+	s := newServer(func(n int, w http.ResponseWriter) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer s.Close()
+
+	_, err := testClient().Get(context.Background(), s.URL)
+	herr, ok := err.(*HTTPError)
+	if !ok || herr.StatusCode != http.StatusNotFound {
+		t.Fatalf("err = %v, want an *HTTPError with status 404", err)
+	}
+	if s.requests() != 1 {
+		t.Errorf("made %d requests, want 1", s.requests())
+	}
+}
+
+func TestCancelDuringBackoff(t *testing.T) {
+	s := newServer(func(n int, w http.ResponseWriter) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	c := &Client{MaxRetries: 3, BaseDelay: time.Hour}
+	began := time.Now()
+	_, err := c.Get(ctx, s.URL)
+	if err != context.Canceled {
+		t.Fatalf("err = %v, want %v", err, context.Canceled)
+	}
+	if took := time.Since(began); took > 5*time.Second {
+		t.Errorf("Get returned after %v, want it to stop waiting when canceled", took)
+	}
+	if s.requests() != 1 {
+		t.Errorf("made %d requests, want 1", s.requests())
+	}
+}
+
+func TestRequestsPerSecond(t *testing.T) {
+	s := newServer(func(n int, w http.ResponseWriter) {
+		w.Write([]byte("ok"))
+	})
+	defer s.Close()
+
+	c := &Client{RequestsPerSecond: 20}
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Get(context.Background(), s.URL); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Requests start 50ms apart; allow for the scheduler.
+	const min = 40 * time.Millisecond
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.times) != 5 {
+		t.Fatalf("made %d requests, want 5", len(s.times))
+	}
+	for i := 1; i < len(s.times); i++ {
+		if gap := s.times[i].Sub(s.times[i-1]); gap < min {
+			t.Errorf("request %d came %v after the one before, want at least %v", i, gap, min)
+		}
+	}
+}
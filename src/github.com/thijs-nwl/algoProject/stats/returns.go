@@ -0,0 +1,83 @@
+package stats
+
+import (
+	"math"
+	"time"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// SimpleReturns returns the change of each close from the close before it,
+// as a fraction. The first value is NaN, as is any return from a zero
+// close. Returns are taken between neighbouring candles, so gaps in the
+// series should be filled with market.Repair first, or the returns across
+// them set to NaN with MaskGaps.
+func SimpleReturns(candles []market.Candle) []float64 {
+	returns := make([]float64, len(candles))
+	for i := range candles {
+		if i == 0 || candles[i-1].Close == 0 {
+			returns[i] = math.NaN()
+			continue
+		}
+		returns[i] = candles[i].Close/candles[i-1].Close - 1
+	}
+	return returns
+}
+
+// LogReturns returns the natural log of each close over the close before
+// it. Log returns add up over time, which makes them the usual input for
+// volatility and correlation. The first value is NaN.
+func LogReturns(candles []market.Candle) []float64 {
+	returns := make([]float64, len(candles))
+	for i := range candles {
+		if i == 0 || candles[i-1].Close <= 0 || candles[i].Close <= 0 {
+			returns[i] = math.NaN()
+			continue
+		}
+		returns[i] = math.Log(candles[i].Close / candles[i-1].Close)
+	}
+	return returns
+}
+
+// MaskGaps sets the returns of candles that do not follow the candle
+// before them by exactly period to NaN, and returns returns. A return
+// across a gap in the series spans more than one period and would distort
+// volatility, autocorrelation and correlation.
+func MaskGaps(returns []float64, candles []market.Candle, period market.Period) []float64 {
+	for i := 1; i < len(candles) && i < len(returns); i++ {
+		if candles[i].Date-candles[i-1].Date != int64(period) {
+			returns[i] = math.NaN()
+		}
+	}
+	return returns
+}
+
+// Drawdowns returns how far each close is below the highest close so far,
+// as a fraction: 0 at a new high and 0.25 a quarter below it.
+func Drawdowns(candles []market.Candle) []float64 {
+	dd := make([]float64, len(candles))
+	peak := math.Inf(-1)
+	for i, c := range candles {
+		peak = math.Max(peak, c.Close)
+		if peak > 0 {
+			dd[i] = 1 - c.Close/peak
+		}
+	}
+	return dd
+}
+
+// MaxDrawdown returns the deepest of the drawdowns.
+func MaxDrawdown(drawdowns []float64) float64 {
+	var max float64
+	for _, d := range finite(drawdowns) {
+		max = math.Max(max, d)
+	}
+	return max
+}
+
+// Annualize scales the volatility of returns over period to a year of 365
+// days, assuming independent returns.
+func Annualize(vol float64, period market.Period) float64 {
+	perYear := (365 * 24 * time.Hour).Seconds() / period.Duration().Seconds()
+	return vol * math.Sqrt(perYear)
+}
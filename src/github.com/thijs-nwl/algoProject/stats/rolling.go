@@ -0,0 +1,77 @@
+package stats
+
+import (
+	"math"
+
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// RollingVolatility returns the sample standard deviation of the last n
+// returns at each position. Values are NaN until n returns are available
+// and wherever the window holds a NaN.
+func RollingVolatility(returns []float64, n int) []float64 {
+	vol := make([]float64, len(returns))
+	for i := range returns {
+		w, ok := window(returns, i, n)
+		if !ok {
+			vol[i] = math.NaN()
+			continue
+		}
+		vol[i] = math.Sqrt(variance(w))
+	}
+	return vol
+}
+
+// RollingCorrelation returns the Pearson correlation of the last n values
+// of a and b at each position. The series must be aligned, as Align does
+// for candles. Values are NaN until n pairs are available, wherever either
+// window holds a NaN and where either window is flat.
+func RollingCorrelation(a, b []float64, n int) []float64 {
+	corr := make([]float64, len(a))
+	for i := range a {
+		x, ok := window(a, i, n)
+		y, ok2 := window(b, i, n)
+		if !ok || !ok2 {
+			corr[i] = math.NaN()
+			continue
+		}
+		corr[i] = correlation(x, y)
+	}
+	return corr
+}
+
+// window returns the n values of x ending at i, and whether they are all
+// defined.
+func window(x []float64, i, n int) ([]float64, bool) {
+	if n < 2 || i >= len(x) || i+1 < n {
+		return nil, false
+	}
+	w := x[i+1-n : i+1]
+	for _, v := range w {
+		if !isFinite(v) {
+			return nil, false
+		}
+	}
+	return w, true
+}
+
+// Align returns the candles of a and b that share a date, so that series
+// derived from them, such as the returns of two pairs, can be compared
+// position by position. Both inputs must be ordered by date.
+func Align(a, b []market.Candle) ([]market.Candle, []market.Candle) {
+	var x, y []market.Candle
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i].Date < b[j].Date:
+			i++
+		case a[i].Date > b[j].Date:
+			j++
+		default:
+			x = append(x, a[i])
+			y = append(y, b[j])
+			i++
+			j++
+		}
+	}
+	return x, y
+}
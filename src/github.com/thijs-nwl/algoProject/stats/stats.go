@@ -0,0 +1,167 @@
+// Package stats describes the distribution of returns over candle series.
+//
+// The functions that derive a series from candles, such as LogReturns and
+// Drawdowns, return one value per candle like the indicators do, with NaN
+// where the value is not defined. The summary statistics skip NaN values,
+// so they can be applied to those series directly.
+package stats
+
+import "math"
+
+// finite returns the values of x that are not NaN or infinite.
+func finite(x []float64) []float64 {
+	var values []float64
+	for _, v := range x {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Mean returns the arithmetic mean of x, or NaN when x is empty.
+func Mean(x []float64) float64 {
+	return mean(finite(x))
+}
+
+func mean(x []float64) float64 {
+	if len(x) == 0 {
+		return math.NaN()
+	}
+	var sum float64
+	for _, v := range x {
+		sum += v
+	}
+	return sum / float64(len(x))
+}
+
+// Variance returns the sample variance of x, or NaN for fewer than two
+// values.
+func Variance(x []float64) float64 {
+	return variance(finite(x))
+}
+
+func variance(x []float64) float64 {
+	if len(x) < 2 {
+		return math.NaN()
+	}
+	m := mean(x)
+	var ss float64
+	for _, v := range x {
+		ss += (v - m) * (v - m)
+	}
+	return ss / float64(len(x)-1)
+}
+
+// StdDev returns the sample standard deviation of x.
+func StdDev(x []float64) float64 {
+	return math.Sqrt(Variance(x))
+}
+
+// moments returns the second, third and fourth central moments of x.
+func moments(x []float64) (m2, m3, m4 float64) {
+	m := mean(x)
+	for _, v := range x {
+		d := v - m
+		m2 += d * d
+		m3 += d * d * d
+		m4 += d * d * d * d
+	}
+	n := float64(len(x))
+	return m2 / n, m3 / n, m4 / n
+}
+
+// Skewness returns the adjusted Fisher-Pearson skewness of x, the sample
+// skewness that spreadsheets and pandas report. It is NaN for fewer than
+// three values or when they are all equal.
+func Skewness(x []float64) float64 {
+	x = finite(x)
+	n := float64(len(x))
+	if n < 3 {
+		return math.NaN()
+	}
+	m2, m3, _ := moments(x)
+	if m2 == 0 {
+		return math.NaN()
+	}
+	return math.Sqrt(n*(n-1)) / (n - 2) * m3 / math.Pow(m2, 1.5)
+}
+
+// Kurtosis returns the sample excess kurtosis of x, which is 0 for a
+// normal distribution and positive for fat tails. It is NaN for fewer than
+// four values or when they are all equal.
+func Kurtosis(x []float64) float64 {
+	x = finite(x)
+	n := float64(len(x))
+	if n < 4 {
+		return math.NaN()
+	}
+	m2, _, m4 := moments(x)
+	if m2 == 0 {
+		return math.NaN()
+	}
+	g2 := m4/(m2*m2) - 3
+	return (n - 1) / ((n - 2) * (n - 3)) * ((n+1)*g2 + 6)
+}
+
+// Autocorrelation returns the correlation of x with itself lag values
+// later, normalized by the variance of the whole series as in the usual
+// autocorrelation function. NaN values keep their position: the mean and
+// variance are taken over the defined values, and a pair of values lag
+// apart only counts when both are defined, so a gap never pairs values
+// further apart than lag.
+func Autocorrelation(x []float64, lag int) float64 {
+	if lag < 0 || lag >= len(x) {
+		return math.NaN()
+	}
+	m := Mean(x)
+	var num, den float64
+	for i, v := range x {
+		if !isFinite(v) {
+			continue
+		}
+		den += (v - m) * (v - m)
+		if i+lag < len(x) && isFinite(x[i+lag]) {
+			num += (v - m) * (x[i+lag] - m)
+		}
+	}
+	if den == 0 || math.IsNaN(m) {
+		return math.NaN()
+	}
+	return num / den
+}
+
+// Correlation returns the Pearson correlation of a and b over the
+// positions where both are defined.
+func Correlation(a, b []float64) float64 {
+	var x, y []float64
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if isFinite(a[i]) && isFinite(b[i]) {
+			x = append(x, a[i])
+			y = append(y, b[i])
+		}
+	}
+	return correlation(x, y)
+}
+
+func correlation(x, y []float64) float64 {
+	if len(x) < 2 {
+		return math.NaN()
+	}
+	mx, my := mean(x), mean(y)
+	var sxy, sxx, syy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return math.NaN()
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
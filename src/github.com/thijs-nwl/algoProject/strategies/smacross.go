@@ -0,0 +1,48 @@
+// Package strategies holds trading strategies for the backtester and the
+// paper trader.
+package strategies
+
+import (
+	"math"
+
+	"github.com/thijs-nwl/algoProject/backtest"
+	"github.com/thijs-nwl/algoProject/indicators"
+	"github.com/thijs-nwl/algoProject/market"
+)
+
+// SMACross goes all in when the fast simple moving average of the close
+// crosses above the slow one, and sells everything when it crosses back
+// below.
+type SMACross struct {
+	fast, slow *indicators.SMA
+	above      bool
+	started    bool
+}
+
+// NewSMACross returns an SMACross over fast and slow candles.
+func NewSMACross(fast, slow int) *SMACross {
+	return &SMACross{fast: indicators.NewSMA(fast), slow: indicators.NewSMA(slow)}
+}
+
+// OnCandle implements backtest.Strategy.
+func (s *SMACross) OnCandle(c market.Candle, p backtest.Portfolio) []backtest.Order {
+	fast, slow := s.fast.Next(c), s.slow.Next(c)
+	if math.IsNaN(fast) || math.IsNaN(slow) {
+		return nil
+	}
+
+	above := fast > slow
+	crossed := s.started && above != s.above
+	s.above, s.started = above, true
+	if !crossed {
+		return nil
+	}
+
+	if above && p.Cash > 0 {
+		return []backtest.Order{{Side: market.Buy, Amount: p.Cash / c.Close}}
+	}
+	if !above && p.Position > 0 {
+		return []backtest.Order{{Side: market.Sell, Amount: p.Position}}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Package timespec reads the points in time the commands take as flags, so
+// that every command accepts the same forms and treats the end of a range
+// the same way.
+package timespec
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayout is the one layout without a time of day.
+const dateLayout = "2006-01-02"
+
+var layouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	dateLayout,
+}
+
+// Parse reads a point in time given as "now", a Unix timestamp, a UTC date
+// such as 2017-12-01, a UTC date and time such as 2017-12-01T15:04 or
+// "2017-12-01 15:04:05", or a duration before now such as "30d",
+// "last 30d", "12h" or "2w". A date alone stands for its first second.
+func Parse(s string, now time.Time) (time.Time, error) {
+	t, _, err := parse(s, now)
+	return t, err
+}
+
+// ParseEnd is Parse for the inclusive end of a range: a date alone stands
+// for the whole day, so it returns the last second of that day. Other
+// forms are read as Parse reads them.
+func ParseEnd(s string, now time.Time) (time.Time, error) {
+	t, dateOnly, err := parse(s, now)
+	if dateOnly {
+		t = t.AddDate(0, 0, 1).Add(-time.Second)
+	}
+	return t, err
+}
+
+// Range reads the start and end of an inclusive range with Parse and
+// ParseEnd. An empty start or end leaves that side open, reaching back to
+// the Unix epoch or forward to the last time a 32 bit Unix time holds.
+func Range(start, end string, now time.Time) (time.Time, time.Time, error) {
+	from, to := time.Unix(0, 0).UTC(), time.Unix(math.MaxInt32, 0).UTC()
+	var err error
+	if start != "" {
+		if from, err = Parse(start, now); err != nil {
+			return from, to, err
+		}
+	}
+	if end != "" {
+		if to, err = ParseEnd(end, now); err != nil {
+			return from, to, err
+		}
+	}
+	return from, to, nil
+}
+
+func parse(s string, now time.Time) (t time.Time, dateOnly bool, err error) {
+	s = strings.TrimSpace(s)
+	if s == "now" {
+		return now, false, nil
+	}
+	if unix, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(unix, 0).UTC(), false, nil
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, layout == dateLayout, nil
+		}
+	}
+	if d, err := parseAgo(strings.TrimSpace(strings.TrimPrefix(s, "last"))); err == nil {
+		return now.Add(-d), false, nil
+	}
+	return time.Time{}, false, fmt.Errorf("invalid time %q", s)
+}
+
+// parseAgo reads a duration like "90m", "12h", "30d" or "2w".
+func parseAgo(s string) (time.Duration, error) {
+	units := map[byte]time.Duration{
+		'm': time.Minute,
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+	}
+	if len(s) < 2 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	unit, ok := units[s[len(s)-1]]
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if !ok || err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return time.Duration(n) * unit, nil
+}